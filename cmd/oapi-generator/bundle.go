@@ -0,0 +1,385 @@
+// Copyright 2022 The go-openapi-tools Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bytes"
+	"flag"
+	"fmt"
+	"os"
+	pathpkg "path"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	json "github.com/goccy/go-json"
+
+	"github.com/zchee/go-openapi-tools/compiler"
+)
+
+// runBundle bundles the schema and its external $ref files into a single schema.
+func runBundle(args []string) int {
+	fs := flag.NewFlagSet("bundle", flag.ExitOnError)
+	out := fs.String("o", "", "Write the bundled schema to the file. writes to stdout if empty")
+	format := fs.String("format", "", fmt.Sprintf("Output format. one of (%s, %s). detected from the -o extension if empty", formatJSON, formatYAML))
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: oapi-generator bundle [flags] <openapi schema file>\n\n")
+		fmt.Fprintf(fs.Output(), "Bundles the OpenAPI 3.0 schema and its external $ref files into a single schema.\n")
+		fmt.Fprintf(fs.Output(), "The external schemas are moved to the components, the other external $ref are inlined.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	outFormat, err := outputFormat(*format, *out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	doc, err := loadDocument(compiler.SchemaNameOpenAPI, fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	newBundler(doc).bundle()
+
+	if err := writeDocument(doc, outFormat, *out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	return exitSuccess
+}
+
+// bundler rewrites the external $ref of the resolved document.
+//
+// The $ref is local if it refers to the component of the root document, which is equal to the resolved value.
+// Otherwise it refers to the external file, or the element of the external file, so that the schema is moved to the
+// components of the root document, and the other value is inlined.
+type bundler struct {
+	doc *openapi3.T
+
+	visited map[*openapi3.Schema]bool
+}
+
+// newBundler returns the bundler of doc, which has the component schemas.
+func newBundler(doc *openapi3.T) *bundler {
+	if doc.Components.Schemas == nil {
+		doc.Components.Schemas = make(openapi3.Schemas)
+	}
+
+	return &bundler{
+		doc:     doc,
+		visited: make(map[*openapi3.Schema]bool),
+	}
+}
+
+// localName returns the component name of the local ref under the kind components, or empty if ref is not local.
+func localName(ref, kind string) string {
+	prefix := "#/components/" + kind + "/"
+	if !strings.HasPrefix(ref, prefix) {
+		return ""
+	}
+	name := strings.TrimPrefix(ref, prefix)
+	if strings.Contains(name, "/") {
+		return ""
+	}
+
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(name)
+}
+
+// equal reports whether the resolved values x and y are the same, or marshaled to the same JSON.
+//
+// The loader resolves the external $ref to the distinct value for each reference, so that compares the content.
+func equal(x, y interface{}) bool {
+	if x == y {
+		return true
+	}
+	xb, err := json.Marshal(x)
+	if err != nil {
+		return false
+	}
+	yb, err := json.Marshal(y)
+	if err != nil {
+		return false
+	}
+
+	return bytes.Equal(xb, yb)
+}
+
+// componentName returns the component name for the external schema ref.
+//
+// The name is derived from ref, and suffixed with the number if the other schema is already named.
+func (b *bundler) componentName(ref string, schema *openapi3.Schema) string {
+	name := ref
+	if idx := strings.LastIndexByte(ref, '#'); idx > -1 {
+		name = pathpkg.Base(ref[idx+1:])
+	}
+	if name == "" || name == "/" || name == "." {
+		file := pathpkg.Base(strings.SplitN(ref, "#", 2)[0])
+		name = strings.TrimSuffix(file, pathpkg.Ext(file))
+	}
+
+	unique := name
+	for i := 2; ; i++ {
+		comp, ok := b.doc.Components.Schemas[unique]
+		if !ok || equal(comp.Value, schema) {
+			return unique
+		}
+		unique = name + strconv.Itoa(i)
+	}
+}
+
+// bundle rewrites the external $ref of the components and the paths.
+func (b *bundler) bundle() {
+	c := b.doc.Components
+	for _, name := range compiler.SortedMapKeys(c.Schemas) {
+		// inline the component which refers to the external schema, instead of moving to the another name
+		if v := c.Schemas[name]; v != nil && v.Value != nil && localName(v.Ref, "schemas") == "" {
+			v.Ref = ""
+		}
+	}
+	for _, name := range compiler.SortedMapKeys(c.Schemas) {
+		b.schema(c.Schemas[name])
+	}
+	for _, name := range compiler.SortedMapKeys(c.Parameters) {
+		b.parameter(c.Parameters[name])
+	}
+	for _, name := range compiler.SortedMapKeys(c.Headers) {
+		b.header(c.Headers[name])
+	}
+	for _, name := range compiler.SortedMapKeys(c.RequestBodies) {
+		b.requestBody(c.RequestBodies[name])
+	}
+	for _, name := range compiler.SortedMapKeys(c.Responses) {
+		b.response(c.Responses[name])
+	}
+	for _, name := range compiler.SortedMapKeys(c.SecuritySchemes) {
+		if v := c.SecuritySchemes[name]; v != nil && v.Value != nil && localName(v.Ref, "securitySchemes") == "" {
+			v.Ref = ""
+		}
+	}
+	for _, name := range compiler.SortedMapKeys(c.Examples) {
+		b.example(c.Examples[name])
+	}
+	for _, name := range compiler.SortedMapKeys(c.Links) {
+		b.link(c.Links[name])
+	}
+	for _, name := range compiler.SortedMapKeys(c.Callbacks) {
+		b.callback(c.Callbacks[name])
+	}
+
+	for _, path := range compiler.SortedMapKeys(b.doc.Paths) {
+		b.pathItem(b.doc.Paths[path])
+	}
+}
+
+// schema moves the external schema of ref to the components, and walks its subschemas.
+func (b *bundler) schema(ref *openapi3.SchemaRef) {
+	if ref == nil || ref.Value == nil {
+		return
+	}
+
+	if ref.Ref != "" {
+		if name := localName(ref.Ref, "schemas"); name != "" {
+			if comp := b.doc.Components.Schemas[name]; comp != nil && equal(comp.Value, ref.Value) {
+				return // walked from the components
+			}
+		}
+		name := b.componentName(ref.Ref, ref.Value)
+		if _, ok := b.doc.Components.Schemas[name]; !ok {
+			b.doc.Components.Schemas[name] = openapi3.NewSchemaRef("", ref.Value)
+		}
+		ref.Ref = "#/components/schemas/" + name
+	}
+
+	if b.visited[ref.Value] {
+		return
+	}
+	b.visited[ref.Value] = true
+
+	v := ref.Value
+	b.schema(v.Items)
+	b.schema(v.Not)
+	b.schema(v.AdditionalProperties)
+	for _, name := range compiler.SortedMapKeys(v.Properties) {
+		b.schema(v.Properties[name])
+	}
+	for _, s := range v.AllOf {
+		b.schema(s)
+	}
+	for _, s := range v.AnyOf {
+		b.schema(s)
+	}
+	for _, s := range v.OneOf {
+		b.schema(s)
+	}
+}
+
+// inline clears ref if ref is not the local ref to the same value of the kind components.
+func (b *bundler) inline(ref *string, kind string, same func(name string) bool) {
+	if *ref == "" {
+		return
+	}
+	if name := localName(*ref, kind); name != "" && same(name) {
+		return
+	}
+	*ref = ""
+}
+
+// parameter inlines the external parameter of ref, and walks its schema, content and examples.
+func (b *bundler) parameter(ref *openapi3.ParameterRef) {
+	if ref == nil || ref.Value == nil {
+		return
+	}
+	b.inline(&ref.Ref, "parameters", func(name string) bool {
+		comp := b.doc.Components.Parameters[name]
+		return comp != nil && equal(comp.Value, ref.Value)
+	})
+
+	b.schema(ref.Value.Schema)
+	b.content(ref.Value.Content)
+	for _, name := range compiler.SortedMapKeys(ref.Value.Examples) {
+		b.example(ref.Value.Examples[name])
+	}
+}
+
+// header inlines the external header of ref, and walks its schema, content and examples.
+func (b *bundler) header(ref *openapi3.HeaderRef) {
+	if ref == nil || ref.Value == nil {
+		return
+	}
+	b.inline(&ref.Ref, "headers", func(name string) bool {
+		comp := b.doc.Components.Headers[name]
+		return comp != nil && equal(comp.Value, ref.Value)
+	})
+
+	b.schema(ref.Value.Schema)
+	b.content(ref.Value.Content)
+	for _, name := range compiler.SortedMapKeys(ref.Value.Examples) {
+		b.example(ref.Value.Examples[name])
+	}
+}
+
+// requestBody inlines the external request body of ref, and walks its content.
+func (b *bundler) requestBody(ref *openapi3.RequestBodyRef) {
+	if ref == nil || ref.Value == nil {
+		return
+	}
+	b.inline(&ref.Ref, "requestBodies", func(name string) bool {
+		comp := b.doc.Components.RequestBodies[name]
+		return comp != nil && equal(comp.Value, ref.Value)
+	})
+
+	b.content(ref.Value.Content)
+}
+
+// response inlines the external response of ref, and walks its headers, content and links.
+func (b *bundler) response(ref *openapi3.ResponseRef) {
+	if ref == nil || ref.Value == nil {
+		return
+	}
+	b.inline(&ref.Ref, "responses", func(name string) bool {
+		comp := b.doc.Components.Responses[name]
+		return comp != nil && equal(comp.Value, ref.Value)
+	})
+
+	for _, name := range compiler.SortedMapKeys(ref.Value.Headers) {
+		b.header(ref.Value.Headers[name])
+	}
+	b.content(ref.Value.Content)
+	for _, name := range compiler.SortedMapKeys(ref.Value.Links) {
+		b.link(ref.Value.Links[name])
+	}
+}
+
+// example inlines the external example of ref.
+func (b *bundler) example(ref *openapi3.ExampleRef) {
+	if ref == nil || ref.Value == nil {
+		return
+	}
+	b.inline(&ref.Ref, "examples", func(name string) bool {
+		comp := b.doc.Components.Examples[name]
+		return comp != nil && equal(comp.Value, ref.Value)
+	})
+}
+
+// link inlines the external link of ref.
+func (b *bundler) link(ref *openapi3.LinkRef) {
+	if ref == nil || ref.Value == nil {
+		return
+	}
+	b.inline(&ref.Ref, "links", func(name string) bool {
+		comp := b.doc.Components.Links[name]
+		return comp != nil && equal(comp.Value, ref.Value)
+	})
+}
+
+// callback inlines the external callback of ref, and walks its path items.
+func (b *bundler) callback(ref *openapi3.CallbackRef) {
+	if ref == nil || ref.Value == nil {
+		return
+	}
+	b.inline(&ref.Ref, "callbacks", func(name string) bool {
+		comp := b.doc.Components.Callbacks[name]
+		return comp != nil && equal(comp.Value, ref.Value)
+	})
+
+	for _, expr := range compiler.SortedMapKeys(*ref.Value) {
+		b.pathItem((*ref.Value)[expr])
+	}
+}
+
+// content walks the schemas, examples and encoding headers of the media types.
+func (b *bundler) content(content openapi3.Content) {
+	for _, mime := range compiler.SortedMapKeys(content) {
+		mt := content[mime]
+		if mt == nil {
+			continue
+		}
+		b.schema(mt.Schema)
+		for _, name := range compiler.SortedMapKeys(mt.Examples) {
+			b.example(mt.Examples[name])
+		}
+		for _, name := range compiler.SortedMapKeys(mt.Encoding) {
+			if enc := mt.Encoding[name]; enc != nil {
+				for _, hdr := range compiler.SortedMapKeys(enc.Headers) {
+					b.header(enc.Headers[hdr])
+				}
+			}
+		}
+	}
+}
+
+// pathItem walks the parameters, request bodies, responses and callbacks of the operations of item.
+func (b *bundler) pathItem(item *openapi3.PathItem) {
+	if item == nil {
+		return
+	}
+	item.Ref = "" // always resolved by the loader
+
+	for _, param := range item.Parameters {
+		b.parameter(param)
+	}
+	ops := item.Operations()
+	for _, method := range compiler.SortedMapKeys(ops) {
+		op := ops[method]
+		for _, param := range op.Parameters {
+			b.parameter(param)
+		}
+		b.requestBody(op.RequestBody)
+		for _, code := range compiler.SortedMapKeys(op.Responses) {
+			b.response(op.Responses[code])
+		}
+		for _, name := range compiler.SortedMapKeys(op.Callbacks) {
+			b.callback(op.Callbacks[name])
+		}
+	}
+}
@@ -0,0 +1,56 @@
+// Copyright 2022 The go-openapi-tools Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"os"
+
+	"github.com/getkin/kin-openapi/openapi2conv"
+)
+
+// runConvert converts the Swagger 2.0 schema to OpenAPI 3.0.
+func runConvert(args []string) int {
+	fs := flag.NewFlagSet("convert", flag.ExitOnError)
+	out := fs.String("o", "", "Write the converted schema to the file. writes to stdout if empty")
+	format := fs.String("format", "", fmt.Sprintf("Output format. one of (%s, %s). detected from the -o extension if empty", formatJSON, formatYAML))
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: oapi-generator convert [flags] <swagger schema file>\n\n")
+		fmt.Fprintf(fs.Output(), "Converts the JSON or YAML Swagger 2.0 schema to OpenAPI 3.0.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	fname := fs.Arg(0)
+
+	outFormat, err := outputFormat(*format, *out)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitUsage
+	}
+
+	swagger, err := loadSwagger(fname)
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	doc, err := openapi2conv.ToV3(swagger)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "failed to convert %s to OpenAPI schema: %v\n", fname, err)
+		return exitError
+	}
+
+	if err := writeDocument(doc, outFormat, *out); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	return exitSuccess
+}
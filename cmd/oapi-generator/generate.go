@@ -0,0 +1,386 @@
+// Copyright 2020 The go-openapi-tools Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"bufio"
+	"errors"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/zchee/go-openapi-tools/compiler"
+	"github.com/zchee/go-openapi-tools/config"
+	"github.com/zchee/go-openapi-tools/diag"
+)
+
+// runGenerate generates the Go API client code from the schema.
+func runGenerate(args []string) int {
+	fs := flag.NewFlagSet("generate", flag.ExitOnError)
+	configFile := fs.String("config", "", fmt.Sprintf("Configuration file. uses %s in the current directory if exists and no schema file is given", config.FileName))
+	schemaType := fs.String("schema", "", fmt.Sprintf("Schema type. one of (%s, %s). overrides the detection from the version field of the schema", compiler.SchemaNameOpenAPI, compiler.SchemaNameSwagger))
+	packageName := fs.String("package", "api", "Generate package name.")
+	out := fs.String("out", ".", "Write schema to specific directory.")
+	clean := fs.Bool("clean", false, "remove the stale generated files, which are listed in the manifest or have the generated code comment of oapi-generator")
+	server := fs.Bool("server", false, "also generate the net/http server interface and router")
+	interfaces := fs.Bool("interfaces", false, "also generate the per-service interfaces")
+	fake := fs.Bool("fake", false, "also generate the in-memory fake package to the fake subdirectory. implies -interfaces")
+	tagMode := fs.String("tag-mode", compiler.TagModeNameFirst, fmt.Sprintf("assigns the operation which has multiple tags to the service of the first tag or all tags, one of (%s, %s)", compiler.TagModeNameFirst, compiler.TagModeNameAll))
+	importPath := fs.String("import-path", "", "import path of the generated package. detected from the go.mod if empty")
+	typeCheck := fs.Bool("typecheck", false, "type-check and vet the generated code before writing the files, and fail if it does not compile or pass vet")
+	templates := fs.String("templates", "", "directory of the *.tmpl files which override the default templates. see \"oapi-generator templates\"")
+	diagFormat := fs.String("diagnostics", diag.FormatText, fmt.Sprintf("format of the diagnostics written to stderr, one of (%s)", strings.Join(diag.Formats, ", ")))
+	verbose := fs.Bool("v", false, "verbose output, logs the progress and reports the informational diagnostics such as the renamed identifiers")
+	quiet := fs.Bool("q", false, "quiet output, reports the errors only")
+	check := fs.Bool("check", false, "do not write files, print the unified diff of the out of date files and exit with non-zero status if any")
+	fs.BoolVar(check, "diff", false, "alias of -check")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: oapi-generator [generate] [flags] [<schema file>]\n\n")
+		fmt.Fprintf(fs.Output(), "Generates the Go API client code from the schema, or the schemas of the configuration file.\n")
+		fmt.Fprintf(fs.Output(), "The flags override the configuration.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if !validFormat(*diagFormat) {
+		fmt.Fprintf(os.Stderr, "unknown diagnostics format %q, one of (%s)\n", *diagFormat, strings.Join(diag.Formats, ", "))
+		return exitUsage
+	}
+	switch *schemaType {
+	case "", compiler.SchemaNameOpenAPI, compiler.SchemaNameSwagger:
+	default:
+		fmt.Fprintf(os.Stderr, "unknown schema type %q, one of (%s, %s)\n", *schemaType, compiler.SchemaNameOpenAPI, compiler.SchemaNameSwagger)
+		return exitUsage
+	}
+	if *verbose && *quiet {
+		fmt.Fprintln(os.Stderr, "-v and -q can not be given together")
+		return exitUsage
+	}
+	level := slog.LevelWarn
+	switch {
+	case *verbose:
+		level = slog.LevelDebug
+	case *quiet:
+		level = slog.LevelError
+	}
+	logger := newLogger(level)
+
+	if *configFile == "" && fs.NArg() == 0 {
+		if _, err := os.Stat(config.FileName); err == nil {
+			*configFile = config.FileName
+		}
+	}
+
+	var specs []*config.Spec
+	switch {
+	case *configFile != "":
+		cfg, err := config.Load(*configFile)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		specs = cfg.Specs
+
+	case fs.NArg() == 0:
+		fs.Usage()
+		return exitUsage
+
+	default:
+		specs = []*config.Spec{{}}
+	}
+
+	if fs.NArg() > 0 {
+		if len(specs) > 1 {
+			fmt.Fprintf(os.Stderr, "schema file can not be given with the configuration of %d specs\n", len(specs))
+			return exitUsage
+		}
+		specs[0].Schema = fs.Arg(0)
+	}
+
+	// overrides the configuration by the flags which explicitly set, or the defaults which not configured
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	for _, spec := range specs {
+		if set["schema"] {
+			spec.SchemaType = *schemaType
+		}
+		if spec.Package == "" || set["package"] {
+			spec.Package = *packageName
+		}
+		if spec.Out == "" || set["out"] {
+			spec.Out = *out
+		}
+		if set["clean"] {
+			spec.Clean = *clean
+		}
+		if set["tag-mode"] {
+			spec.TagMode = *tagMode
+		}
+		if set["import-path"] {
+			spec.ImportPath = *importPath
+		}
+		if set["templates"] {
+			spec.Templates = *templates
+		}
+		if set["typecheck"] {
+			spec.TypeCheck = *typeCheck
+		}
+		if set["server"] || set["interfaces"] || set["fake"] {
+			if spec.Generate == nil {
+				spec.Generate = new(config.Artifacts)
+			}
+			if set["server"] {
+				spec.Generate.Server = *server
+			}
+			if set["interfaces"] {
+				spec.Generate.Interfaces = *interfaces
+			}
+			if set["fake"] {
+				spec.Generate.Mocks = *fake
+			}
+		}
+	}
+
+	run := generateSpec
+	if *check {
+		run = checkSpec
+	}
+
+	code := exitSuccess
+	var diags diag.List
+	for _, spec := range specs {
+		d, err := run(spec, logger)
+		diags = append(diags, d...)
+		if err != nil {
+			diags = append(diags, errorDiagnostics(spec.Schema, err)...)
+			code = exitError
+			if !*check {
+				break
+			}
+		}
+	}
+	if err := diag.Write(os.Stderr, *diagFormat, diags.Filter(level)); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	return code
+}
+
+// newLogger returns the logger which writes the records of level or higher to stderr, without the time.
+func newLogger(level slog.Level) *slog.Logger {
+	return slog.New(slog.NewTextHandler(os.Stderr, &slog.HandlerOptions{
+		Level: level,
+		ReplaceAttr: func(groups []string, a slog.Attr) slog.Attr {
+			if len(groups) == 0 && a.Key == slog.TimeKey {
+				return slog.Attr{}
+			}
+			return a
+		},
+	}))
+}
+
+// validFormat reports whether format is one of diag.Formats.
+func validFormat(format string) bool {
+	for _, f := range diag.Formats {
+		if f == format {
+			return true
+		}
+	}
+
+	return false
+}
+
+// errorDiagnostics returns the diagnostics of err. The error which is not located in the schema is the diagnostic of
+// the schema file.
+func errorDiagnostics(schema string, err error) diag.List {
+	var diags diag.List
+	if errors.As(err, &diags) {
+		return diags
+	}
+	var d *diag.Diagnostic
+	if errors.As(err, &d) {
+		return diag.List{d}
+	}
+
+	return diag.List{{Severity: diag.Error, Msg: err.Error(), File: schema}}
+}
+
+// checkSpec checks whether the generated code of spec is up to date, and prints the unified diff if not.
+func checkSpec(spec *config.Spec, logger *slog.Logger) (diag.List, error) {
+	opts, err := specOptions(spec)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, compiler.WithLogger(logger))
+	g, err := compiler.New(spec.SchemaType, spec.Package, spec.Schema, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	diffs, err := g.Check(spec.Out)
+	if err != nil {
+		return nil, err
+	}
+	for _, d := range diffs {
+		os.Stdout.Write(d.Diff)
+	}
+	if len(diffs) > 0 {
+		return g.Diagnostics(), fmt.Errorf("%d generated files in %s are out of date", len(diffs), spec.Out)
+	}
+
+	return g.Diagnostics(), nil
+}
+
+// generateSpec generates the Go code of spec, and returns the diagnostics of the generation.
+func generateSpec(spec *config.Spec, logger *slog.Logger) (diag.List, error) {
+	opts, err := specOptions(spec)
+	if err != nil {
+		return nil, err
+	}
+	opts = append(opts, compiler.WithLogger(logger))
+
+	g, err := compiler.New(spec.SchemaType, spec.Package, spec.Schema, opts...)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := g.Generate(spec.Out); err != nil {
+		return g.Diagnostics(), err
+	}
+
+	return g.Diagnostics(), nil
+}
+
+// specOptions returns the compiler options of spec.
+func specOptions(spec *config.Spec) ([]compiler.Option, error) {
+	var opts []compiler.Option
+	if spec.Clean {
+		opts = append(opts, compiler.WithClean())
+	}
+
+	gen := spec.Generate
+	if !gen.GenerateClient() {
+		opts = append(opts, compiler.WithoutClient())
+	}
+	if !gen.GenerateModels() {
+		opts = append(opts, compiler.WithoutModels())
+	}
+	if gen != nil && gen.Server {
+		opts = append(opts, compiler.WithServer())
+	}
+	if gen != nil && gen.Interfaces {
+		opts = append(opts, compiler.WithInterfaces())
+	}
+	if gen != nil && gen.Mocks {
+		path := spec.ImportPath
+		if path == "" {
+			var err error
+			path, err = detectImportPath(spec.Out)
+			if err != nil {
+				return nil, err
+			}
+		}
+		opts = append(opts, compiler.WithFake(path))
+	}
+
+	if spec.TagMode != "" {
+		mode, err := compiler.ParseTagMode(spec.TagMode)
+		if err != nil {
+			return nil, err
+		}
+		opts = append(opts, compiler.WithTagMode(mode))
+	}
+	if spec.Include != nil || spec.Exclude != nil {
+		opts = append(opts, compiler.WithFilter(compilerFilter(spec.Include), compilerFilter(spec.Exclude)))
+	}
+	if len(spec.TypeMappings) > 0 {
+		opts = append(opts, compiler.WithTypeMappings(spec.TypeMappings))
+	}
+	if spec.Templates != "" {
+		opts = append(opts, compiler.WithTemplateDir(spec.Templates))
+	}
+	if spec.TypeCheck {
+		opts = append(opts, compiler.WithTypeCheck())
+	}
+	if spec.Naming != nil {
+		if len(spec.Naming.Initialisms) > 0 {
+			opts = append(opts, compiler.WithInitialisms(spec.Naming.Initialisms...))
+		}
+		if len(spec.Naming.Operations) > 0 {
+			opts = append(opts, compiler.WithOperationNames(spec.Naming.Operations))
+		}
+		if t := spec.Naming.TrimGetPrefix; t != nil && !*t {
+			opts = append(opts, compiler.WithoutGetPrefixTrim())
+		}
+	}
+
+	return opts, nil
+}
+
+// compilerFilter converts the filter of the configuration to compiler.Filter.
+func compilerFilter(f *config.Filter) compiler.Filter {
+	if f == nil {
+		return compiler.Filter{}
+	}
+
+	return compiler.Filter{
+		Tags:       f.Tags,
+		Operations: f.Operations,
+		Paths:      f.Paths,
+	}
+}
+
+// detectImportPath detects the import path of dir from the nearest go.mod.
+func detectImportPath(dir string) (string, error) {
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		return "", fmt.Errorf("failed to get absolute path of %s: %w", dir, err)
+	}
+
+	for root := abs; ; root = filepath.Dir(root) {
+		modPath, err := modulePath(filepath.Join(root, "go.mod"))
+		if err == nil {
+			rel, err := filepath.Rel(root, abs)
+			if err != nil {
+				return "", fmt.Errorf("failed to get relative path of %s: %w", abs, err)
+			}
+			if rel == "." {
+				return modPath, nil
+			}
+			return modPath + "/" + filepath.ToSlash(rel), nil
+		}
+		if !errors.Is(err, os.ErrNotExist) {
+			return "", err
+		}
+		if filepath.Dir(root) == root {
+			return "", fmt.Errorf("could not detect import path of %s: go.mod not found, use -import-path", dir)
+		}
+	}
+}
+
+// modulePath returns the module path declared in the gomod file.
+func modulePath(gomod string) (string, error) {
+	f, err := os.Open(gomod)
+	if err != nil {
+		return "", err
+	}
+	defer f.Close()
+
+	sc := bufio.NewScanner(f)
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if strings.HasPrefix(line, "module ") {
+			return strings.Trim(strings.TrimSpace(strings.TrimPrefix(line, "module ")), `"`), nil
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", gomod, err)
+	}
+
+	return "", fmt.Errorf("module path not found in %s", gomod)
+}
@@ -0,0 +1,147 @@
+// Copyright 2022 The go-openapi-tools Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi2"
+	"github.com/getkin/kin-openapi/openapi2conv"
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/ghodss/yaml"
+	json "github.com/goccy/go-json"
+
+	"github.com/zchee/go-openapi-tools/compiler"
+	"github.com/zchee/go-openapi-tools/diag"
+	"github.com/zchee/go-openapi-tools/internal/openapi31"
+	"github.com/zchee/go-openapi-tools/internal/schemaformat"
+)
+
+const (
+	formatJSON = "json"
+	formatYAML = "yaml"
+)
+
+// loadDocument loads the JSON or YAML schema file of schemaType and resolves its $ref, includes the external files.
+//
+// The Swagger 2.0 schema is converted to OpenAPI 3.0. The uuid, ipv4 and ipv6 formats are defined for the validation of
+// the document.
+func loadDocument(schemaType, filename string) (*openapi3.T, error) {
+	schemaformat.Define()
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+
+	switch schemaType {
+	case compiler.SchemaNameOpenAPI:
+		doc, err := loader.LoadFromFile(filename)
+		if err != nil {
+			return nil, fmt.Errorf("failed to load %s: %w", filename, err)
+		}
+		return doc, nil
+
+	case compiler.SchemaNameSwagger:
+		swagger, err := loadSwagger(filename)
+		if err != nil {
+			return nil, err
+		}
+		doc, err := openapi2conv.ToV3(swagger)
+		if err != nil {
+			return nil, fmt.Errorf("failed to convert %s to OpenAPI schema: %w", filename, err)
+		}
+		if err := loader.ResolveRefsIn(doc, nil); err != nil {
+			return nil, fmt.Errorf("failed to resolve %s: %w", filename, err)
+		}
+		return doc, nil
+
+	default:
+		return nil, fmt.Errorf("unknown schema type: %s", schemaType)
+	}
+}
+
+// loadOpenAPI31 converts the OpenAPI 3.1 schema data of filename to OpenAPI 3.0, and loads it with resolving its $ref.
+//
+// The returned diagnostics are the warnings of the conversion. The external $ref files are loaded as is.
+func loadOpenAPI31(filename string, data []byte) (*openapi3.T, diag.List, error) {
+	converted, diags, err := openapi31.Convert(data)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to convert %s: %w", filename, err)
+	}
+
+	loader := openapi3.NewLoader()
+	loader.IsExternalRefsAllowed = true
+	doc, err := loader.LoadFromDataWithPath(converted, &url.URL{Path: filepath.ToSlash(filename)})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to load %s: %w", filename, err)
+	}
+
+	return doc, diags, nil
+}
+
+// loadSwagger loads the JSON or YAML Swagger 2.0 schema file.
+func loadSwagger(filename string) (*openapi2.T, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	var swagger openapi2.T
+	if err := yaml.Unmarshal(data, &swagger); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", filename, err)
+	}
+
+	return &swagger, nil
+}
+
+// outputFormat returns format if not empty, otherwise the format detected by the extension of filename.
+func outputFormat(format, filename string) (string, error) {
+	if format == "" {
+		switch strings.ToLower(filepath.Ext(filename)) {
+		case ".yaml", ".yml":
+			return formatYAML, nil
+		default:
+			return formatJSON, nil
+		}
+	}
+
+	switch format {
+	case formatJSON, formatYAML:
+		return format, nil
+	default:
+		return "", fmt.Errorf("unknown format %q: one of (%s, %s)", format, formatJSON, formatYAML)
+	}
+}
+
+// writeDocument writes doc to filename with format. If filename is empty or "-", writes to stdout.
+func writeDocument(doc interface{}, format, filename string) error {
+	data, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal schema: %w", err)
+	}
+
+	switch format {
+	case formatYAML:
+		data, err = yaml.JSONToYAML(data)
+		if err != nil {
+			return fmt.Errorf("failed to convert schema to YAML: %w", err)
+		}
+	default:
+		data = append(data, '\n')
+	}
+
+	if filename == "" || filename == "-" {
+		_, err := os.Stdout.Write(data)
+		return err
+	}
+
+	if err := os.WriteFile(filename, data, 0666); err != nil {
+		return fmt.Errorf("failed to write %s: %w", filename, err)
+	}
+
+	return nil
+}
@@ -2,15 +2,30 @@
 // SPDX-License-Identifier: BSD-3-Clause
 
 // Command oapi-generator generates the Go API client code from OpenAPI or Swagger schema.
+//
+// Usage:
+//
+//	oapi-generator <command> [flags] <schema file>
+//
+// The commands are:
+//
+//	generate  generate the Go API client code (default)
+//	validate  validate the schema
+//	convert   convert the Swagger 2.0 schema to OpenAPI 3.0
+//	bundle    inline the external $ref into a single schema
+//	mock      serve the mock server of the schema
+//	templates list or write the default templates of the generated code
+//
+// If the command is omitted, the flags are parsed as the generate command. If no arguments are given and
+// oapi-generator.yaml exists in the current directory, generates the schemas of the configuration.
 package main
 
 import (
-	"flag"
 	"fmt"
 	"log"
 	"os"
 
-	"github.com/zchee/go-openapi-tools/compiler"
+	"github.com/zchee/go-openapi-tools/config"
 )
 
 const (
@@ -19,48 +34,58 @@ const (
 	exitUsage
 )
 
-var (
-	flagSchemaType  string
-	flagPackageName string
-	flagOut         string
-	flagClean       bool
-)
+// command represents a subcommand of oapi-generator.
+type command struct {
+	name  string
+	short string
+	run   func(args []string) int
+}
 
-func init() {
-	flag.StringVar(&flagSchemaType, "schema", compiler.SchemaNameOpenAPI, fmt.Sprintf("Schema type. one of (%s, %s)", compiler.SchemaNameOpenAPI, compiler.SchemaNameSwagger))
-	flag.StringVar(&flagPackageName, "package", "api", "Generate package name.")
-	flag.StringVar(&flagOut, "out", ".", "Write schema to specific directory.")
-	flag.BoolVar(&flagClean, "clean", false, "clean generated files before generation")
+var commands = []*command{
+	{name: "generate", short: "generate the Go API client code (default)", run: runGenerate},
+	{name: "validate", short: "validate the schema", run: runValidate},
+	{name: "convert", short: "convert the Swagger 2.0 schema to OpenAPI 3.0", run: runConvert},
+	{name: "bundle", short: "inline the external $ref into a single schema", run: runBundle},
+	{name: "mock", short: "serve the mock server of the schema", run: runMock},
+	{name: "templates", short: "list or write the default templates of the generated code", run: runTemplates},
+}
+
+func usage() {
+	fmt.Fprintf(os.Stderr, "Usage: oapi-generator <command> [flags] <schema file>\n\n")
+	fmt.Fprintf(os.Stderr, "The commands are:\n\n")
+	for _, cmd := range commands {
+		fmt.Fprintf(os.Stderr, "\t%-10s%s\n", cmd.name, cmd.short)
+	}
+	fmt.Fprintf(os.Stderr, "\nIf the command is omitted, the flags are parsed as the generate command.\n")
+	fmt.Fprintf(os.Stderr, "If no arguments are given, generates the schemas of %s in the current directory.\n", config.FileName)
+	fmt.Fprintf(os.Stderr, "Use \"oapi-generator <command> -h\" for more information about a command.\n")
 }
 
 func main() {
-	flag.Parse()
 	log.SetFlags(0)
 	log.SetPrefix("oapi-generator: ")
 
-	if flag.NArg() == 0 {
-		flag.Usage()
+	args := os.Args[1:]
+	if len(args) == 0 {
+		if _, err := os.Stat(config.FileName); err == nil {
+			os.Exit(runGenerate(args))
+		}
+		usage()
 		os.Exit(exitUsage)
 	}
-	fname := flag.Arg(0)
 
-	if flagClean {
-		if _, err := os.Stat(flagOut); os.IsExist(err) {
-			if err := os.Remove(flagOut); err != nil {
-				fmt.Fprintln(os.Stderr, err)
-				os.Exit(exitError)
-			}
-		}
+	switch args[0] {
+	case "help", "-h", "-help", "--help":
+		usage()
+		os.Exit(exitSuccess)
 	}
 
-	g, err := compiler.New(flagSchemaType, flagPackageName, fname)
-	if err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(exitError)
+	for _, cmd := range commands {
+		if args[0] == cmd.name {
+			os.Exit(cmd.run(args[1:]))
+		}
 	}
 
-	if err := g.Generate(flagOut); err != nil {
-		fmt.Fprintln(os.Stderr, err)
-		os.Exit(exitError)
-	}
+	// keep the flag based usage working as the generate command
+	os.Exit(runGenerate(args))
 }
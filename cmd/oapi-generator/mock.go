@@ -0,0 +1,62 @@
+// Copyright 2022 The go-openapi-tools Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/zchee/go-openapi-tools/compiler"
+	"github.com/zchee/go-openapi-tools/mock"
+)
+
+// runMock runs the mock server which serves the responses built from the schema examples.
+func runMock(args []string) int {
+	fs := flag.NewFlagSet("mock", flag.ExitOnError)
+	schemaType := fs.String("schema", "", fmt.Sprintf("Schema type. one of (%s, %s). overrides the detection from the version field of the schema", compiler.SchemaNameOpenAPI, compiler.SchemaNameSwagger))
+	addr := fs.String("addr", "localhost:8080", "Listen address of the mock server.")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: oapi-generator mock [flags] <schema file>\n\n")
+		fmt.Fprintf(fs.Output(), "Serves the responses built from the schema examples. Select the response with \"Prefer: code=404\" or \"Prefer: example=name\" request header.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+
+	g, err := compiler.New(*schemaType, "", fs.Arg(0))
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	h, err := mock.NewHandler(g.Document())
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	srv := &http.Server{
+		Addr: *addr,
+		Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			log.Printf("%s %s", r.Method, r.URL)
+			h.ServeHTTP(w, r)
+		}),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("serving mock server on http://%s", *addr)
+	if err := srv.ListenAndServe(); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	return exitSuccess
+}
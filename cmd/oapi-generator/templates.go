@@ -0,0 +1,78 @@
+// Copyright 2022 The go-openapi-tools Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"flag"
+	"fmt"
+	iofs "io/fs"
+	"os"
+	"path/filepath"
+
+	"github.com/zchee/go-openapi-tools/compiler"
+)
+
+// runTemplates lists, prints or writes the default templates of the generated code.
+func runTemplates(args []string) int {
+	fs := flag.NewFlagSet("templates", flag.ExitOnError)
+	out := fs.String("o", "", "Write the templates to the directory, to be customized and given by generate -templates")
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: oapi-generator templates [flags] [<template name>...]\n\n")
+		fmt.Fprintf(fs.Output(), "Lists the default templates of the generated code, or prints the named templates.\n")
+		fmt.Fprintf(fs.Output(), "With -o, writes the named templates, or all templates if no names are given, to the directory.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	tmpls := compiler.DefaultTemplates()
+	names := fs.Args()
+	if len(names) == 0 {
+		all, err := templateNames(tmpls)
+		if err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return exitError
+		}
+		if *out == "" {
+			for _, name := range all {
+				fmt.Println(name)
+			}
+			return exitSuccess
+		}
+		names = all
+	}
+
+	if *out != "" {
+		if err := os.MkdirAll(*out, 0755); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to MkdirAll %s: %v\n", *out, err)
+			return exitError
+		}
+	}
+	for _, name := range names {
+		data, err := iofs.ReadFile(tmpls, name)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "failed to read %s template: %v\n", name, err)
+			return exitError
+		}
+		if *out == "" {
+			os.Stdout.Write(data)
+			continue
+		}
+		if err := os.WriteFile(filepath.Join(*out, name), data, 0666); err != nil {
+			fmt.Fprintf(os.Stderr, "failed to write %s template: %v\n", name, err)
+			return exitError
+		}
+	}
+
+	return exitSuccess
+}
+
+// templateNames returns the names of the templates in tmpls.
+func templateNames(tmpls iofs.FS) ([]string, error) {
+	names, err := iofs.Glob(tmpls, "*.tmpl")
+	if err != nil {
+		return nil, fmt.Errorf("failed to list templates: %w", err)
+	}
+
+	return names, nil
+}
@@ -0,0 +1,196 @@
+// Copyright 2022 The go-openapi-tools Authors
+// SPDX-License-Identifier: BSD-3-Clause
+
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	"github.com/zchee/go-openapi-tools/compiler"
+	"github.com/zchee/go-openapi-tools/diag"
+	"github.com/zchee/go-openapi-tools/internal/srcmap"
+)
+
+// runValidate loads and validates the schema, and reports the errors with the file and line.
+func runValidate(args []string) int {
+	fs := flag.NewFlagSet("validate", flag.ExitOnError)
+	schemaType := fs.String("schema", "", fmt.Sprintf("Schema type. one of (%s, %s). overrides the detection from the version field of the schema", compiler.SchemaNameOpenAPI, compiler.SchemaNameSwagger))
+	diagFormat := fs.String("diagnostics", diag.FormatText, fmt.Sprintf("format of the diagnostics written to stderr, one of (%s)", strings.Join(diag.Formats, ", ")))
+	fs.Usage = func() {
+		fmt.Fprintf(fs.Output(), "Usage: oapi-generator validate [flags] <schema file>...\n\n")
+		fmt.Fprintf(fs.Output(), "Validates the JSON or YAML schema, includes the external $ref files.\n\n")
+		fs.PrintDefaults()
+	}
+	fs.Parse(args)
+
+	if fs.NArg() == 0 {
+		fs.Usage()
+		return exitUsage
+	}
+	if !validFormat(*diagFormat) {
+		fmt.Fprintf(os.Stderr, "unknown diagnostics format %q, one of (%s)\n", *diagFormat, strings.Join(diag.Formats, ", "))
+		return exitUsage
+	}
+
+	code := exitSuccess
+	var diags diag.List
+	for _, fname := range fs.Args() {
+		d, err := validateFile(*schemaType, fname)
+		if err != nil {
+			d = errorDiagnostics(fname, err)
+		}
+		if d.HasErrors() {
+			code = exitError
+		}
+		diags = append(diags, d...)
+	}
+	if err := diag.Write(os.Stderr, *diagFormat, diags); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return exitError
+	}
+
+	return code
+}
+
+// yamlErrorRe matches the syntax error of YAML parser to extract the line number.
+var yamlErrorRe = regexp.MustCompile(`^yaml: line (\d+): (.*)$`)
+
+// validateFile validates the fname schema file and returns the diagnostics.
+func validateFile(schemaType, fname string) (diag.List, error) {
+	data, err := os.ReadFile(fname)
+	if err != nil {
+		return nil, err
+	}
+
+	sm, err := srcmap.Parse(data)
+	if err != nil {
+		d := &diag.Diagnostic{Severity: diag.Error, Msg: err.Error(), File: fname}
+		if m := yamlErrorRe.FindStringSubmatch(err.Error()); m != nil {
+			line, _ := strconv.Atoi(m[1])
+			d.Line, d.Column, d.Msg = line, 1, m[2]
+		}
+		return diag.List{d}, nil
+	}
+
+	if schemaType == "" {
+		if schemaType, err = compiler.DetectSchemaType(data); err != nil {
+			d := &diag.Diagnostic{Severity: diag.Error, Msg: err.Error(), File: fname}
+			for _, key := range []string{compiler.SchemaNameSwagger, compiler.SchemaNameOpenAPI} {
+				if pos, ok := sm["/"+key]; ok {
+					d.Pointer, d.Line, d.Column = diag.Pointer(key), pos.Line, pos.Column
+				}
+			}
+			return diag.List{d}, nil
+		}
+	}
+
+	var (
+		doc   *openapi3.T
+		diags diag.List
+	)
+	if schemaType == compiler.SchemaNameOpenAPI && compiler.IsOpenAPI31(data) {
+		doc, diags, err = loadOpenAPI31(fname, data)
+	} else {
+		doc, err = loadDocument(schemaType, fname)
+	}
+	if err != nil {
+		return diag.List{{Severity: diag.Error, Msg: err.Error(), File: fname}}, nil
+	}
+
+	diags = append(diags, validateDocument(context.Background(), doc)...)
+	var pointer func(string) string
+	if schemaType == compiler.SchemaNameSwagger {
+		pointer = diag.SwaggerPointer
+	}
+	diags.Locate(fname, data, pointer)
+	diags.Sort()
+
+	return diags, nil
+}
+
+// validateDocument validates each element of doc to locate the errors.
+//
+// If no element is invalid but doc is invalid, the error is located at the root of doc.
+func validateDocument(ctx context.Context, doc *openapi3.T) diag.List {
+	var errs diag.List
+	check := func(err error, tokens ...string) {
+		if err != nil {
+			errs = append(errs, diag.Errorf(diag.Pointer(tokens...), "%v", err))
+		}
+	}
+
+	if doc.OpenAPI == "" {
+		check(fmt.Errorf("value of openapi must be a non-empty string"))
+	}
+	if doc.Info == nil {
+		check(fmt.Errorf("must have an info object"))
+	} else {
+		check(doc.Info.Validate(ctx), "info")
+	}
+
+	c := doc.Components
+	for _, name := range compiler.SortedMapKeys(c.Schemas) {
+		if v := c.Schemas[name]; v != nil {
+			check(v.Validate(ctx), "components", "schemas", name)
+		}
+	}
+	for _, name := range compiler.SortedMapKeys(c.Parameters) {
+		if v := c.Parameters[name]; v != nil {
+			check(v.Validate(ctx), "components", "parameters", name)
+		}
+	}
+	for _, name := range compiler.SortedMapKeys(c.RequestBodies) {
+		if v := c.RequestBodies[name]; v != nil {
+			check(v.Validate(ctx), "components", "requestBodies", name)
+		}
+	}
+	for _, name := range compiler.SortedMapKeys(c.Responses) {
+		if v := c.Responses[name]; v != nil {
+			check(v.Validate(ctx), "components", "responses", name)
+		}
+	}
+	for _, name := range compiler.SortedMapKeys(c.Headers) {
+		if v := c.Headers[name]; v != nil {
+			check(v.Validate(ctx), "components", "headers", name)
+		}
+	}
+	for _, name := range compiler.SortedMapKeys(c.SecuritySchemes) {
+		if v := c.SecuritySchemes[name]; v != nil {
+			check(v.Validate(ctx), "components", "securitySchemes", name)
+		}
+	}
+
+	opErrs := len(errs)
+	for _, path := range compiler.SortedMapKeys(doc.Paths) {
+		item := doc.Paths[path]
+		if item == nil {
+			continue
+		}
+		for _, param := range item.Parameters {
+			if param != nil {
+				check(param.Validate(ctx), "paths", path, "parameters")
+			}
+		}
+		ops := item.Operations()
+		for _, method := range compiler.SortedMapKeys(ops) {
+			check(ops[method].Validate(ctx), "paths", path, strings.ToLower(method))
+		}
+	}
+	if len(errs) == opErrs {
+		check(doc.Paths.Validate(ctx), "paths")
+	}
+
+	if len(errs) == 0 {
+		check(doc.Validate(ctx))
+	}
+
+	return errs
+}
@@ -0,0 +1,144 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"github.com/zchee/go-openapi-tools/internal/diff"
+)
+
+// isGenerated reports whether the Go source data has the generated code comment of oapi-generator before the package
+// clause. The files generated by the other generators, such as stringer, are not.
+func isGenerated(data []byte) bool {
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := sc.Bytes()
+		if string(line) == headerFmt {
+			return true
+		}
+		if bytes.HasPrefix(line, []byte("package ")) {
+			return false
+		}
+	}
+
+	return false
+}
+
+// FileDiff represents the difference between the generated file and the file on disk.
+type FileDiff struct {
+	// Name is the file name relative to the output directory.
+	Name string
+	// Diff is the unified diff from the file on disk to the generated file.
+	Diff []byte
+}
+
+// Check generates the files in memory and compares them against the files under dst, without writing any files.
+//
+// The generated files under dst, which are no longer generated, are reported as removed.
+// The result is sorted by the file name, and empty if the files under dst are up to date.
+func (g *Generator) Check(dst string) ([]*FileDiff, error) {
+	if err := g.generate(); err != nil {
+		return nil, fmt.Errorf("failed to generate: %w", err)
+	}
+
+	stale, err := g.staleFiles(dst)
+	if err != nil {
+		return nil, err
+	}
+
+	var diffs []*FileDiff
+	for _, name := range SortedMapKeys(g.files) {
+		old, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		oldName := "a/" + filepath.ToSlash(name)
+		if err != nil {
+			oldName = "/dev/null"
+		}
+		if d := diff.Unified(oldName, "b/"+filepath.ToSlash(name), old, g.files[name]); d != nil {
+			diffs = append(diffs, &FileDiff{Name: name, Diff: d})
+		}
+	}
+
+	for _, name := range stale {
+		old, err := os.ReadFile(filepath.Join(dst, name))
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", name, err)
+		}
+		diffs = append(diffs, &FileDiff{
+			Name: name,
+			Diff: diff.Unified("a/"+filepath.ToSlash(name), "/dev/null", old, nil),
+		})
+	}
+	sort.SliceStable(diffs, func(i, j int) bool { return diffs[i].Name < diffs[j].Name })
+	g.logger.Info("checked generated files", "dir", dst, "files", len(g.files), "outdated", len(diffs))
+
+	return diffs, nil
+}
+
+// staleFiles returns the generated files under dst which are not generated by g.
+//
+// The generated files are the files listed in the manifest under dst, and the Go files which have the generated code
+// comment of oapi-generator in dst and the subdirectories which the Generator writes to, such as the files generated
+// before the manifest.
+func (g *Generator) staleFiles(dst string) ([]string, error) {
+	seen := make(map[string]bool)
+	var stale []string
+
+	listed, err := readManifest(dst)
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range listed {
+		if _, ok := g.files[name]; ok || seen[name] {
+			continue
+		}
+		fi, err := os.Stat(filepath.Join(dst, name))
+		if err != nil || !fi.Mode().IsRegular() {
+			continue // already removed, or not a file
+		}
+		seen[name] = true
+		stale = append(stale, name)
+	}
+
+	for _, dir := range []string{".", filepath.Dir(fakeFileName)} {
+		entries, err := os.ReadDir(filepath.Join(dst, dir))
+		if err != nil {
+			if errors.Is(err, fs.ErrNotExist) {
+				continue
+			}
+			return nil, fmt.Errorf("failed to read directory %s: %w", dir, err)
+		}
+
+		for _, entry := range entries {
+			name := filepath.Join(dir, entry.Name())
+			if !entry.Type().IsRegular() || filepath.Ext(name) != ".go" {
+				continue
+			}
+			if _, ok := g.files[name]; ok || seen[name] {
+				continue
+			}
+			data, err := os.ReadFile(filepath.Join(dst, name))
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", name, err)
+			}
+			if isGenerated(data) {
+				seen[name] = true
+				stale = append(stale, name)
+			}
+		}
+	}
+	sort.Strings(stale)
+
+	return stale, nil
+}
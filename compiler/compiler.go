@@ -4,53 +4,49 @@
 package compiler
 
 import (
-	"bufio"
 	"bytes"
 	"errors"
 	"fmt"
-	goformat "go/format"
+	"io"
+	"log/slog"
+	"math"
 	"os"
-	pathpkg "path"
-	"path/filepath"
 	"sort"
+	"strconv"
 	"strings"
-	"sync"
+	"text/template"
 
 	"github.com/getkin/kin-openapi/jsoninfo"
 	"github.com/getkin/kin-openapi/openapi2"
 	"github.com/getkin/kin-openapi/openapi2conv"
 	"github.com/getkin/kin-openapi/openapi3"
-	"github.com/getkin/kin-openapi/openapi3filter"
 	"github.com/getkin/kin-openapi/openapi3gen"
-	"github.com/getkin/kin-openapi/routers"
+	"github.com/ghodss/yaml"
 	json "github.com/goccy/go-json"
 	"github.com/iancoleman/strcase"
 	"github.com/klauspost/compress/gzip"
+
+	"github.com/zchee/go-openapi-tools/diag"
+	"github.com/zchee/go-openapi-tools/internal/openapi31"
+	"github.com/zchee/go-openapi-tools/ir"
 )
 
 // keep related packages on import section.
 var (
 	_ jsoninfo.StrictStruct
 	_ = openapi2conv.ToV3
-	_ openapi3filter.ParseErrorKind
 	_ openapi3gen.Generator
-	_ routers.Route
 )
 
 const (
-	docFileName    = "doc.go"
-	clientFileName = "client.go"
-	utilsFileName  = "utils.go"
+	docFileName      = "doc.go"
+	clientFileName   = "client.go"
+	serverFileName   = "server.go"
+	webhooksFileName = "webhooks.go"
+	fakeFileName     = "fake/fake.go"
+	utilsFileName    = "utils.go"
 )
 
-// printFn writes raw or with newline string.
-type printFn func(format string, args ...interface{})
-
-// PathItemsMap is the map of PathItems.
-//  key:   path
-//  value: []*openapi3.PathItem
-type PathItemsMap map[string][]*openapi3.PathItem
-
 const (
 	SchemaNameSwagger = "swagger"
 	SchemaNameOpenAPI = "openapi"
@@ -87,96 +83,235 @@ var schemaTypeMap = map[string]schemaType{
 	SchemaNameOpenAPI: openAPISchema,
 }
 
-func parseSchemaType(schemaType, filename string) (schemaType, error) {
-	schemaType = strings.ToLower(schemaType)
-	if st, ok := schemaTypeMap[schemaType]; ok {
-		return st, nil
+// Generator represents a Go source generator from OpenAPI.
+type Generator struct {
+	openAPI    *openapi3.T
+	schemaType schemaType
+	pkgName    string
+	filename   string // schema file name read by New
+	source     []byte // schema data read by New or NewFromReader, which locates the diagnostics
+
+	templates   *template.Template // templates of the generated files
+	templateDir string             // directory of the templates which override the defaults
+	files       map[string][]byte
+
+	api *ir.API // intermediate representation of the API which the generated code is built from
+
+	server     bool   // generate server interface and router
+	interfaces bool   // generate per service interfaces
+	fake       string // import path of the generated package, generate fake subpackage if not empty
+	skipClient bool   // do not generate the API client
+	skipModels bool   // do not generate the models
+	clean      bool   // remove the stale generated files
+	typeCheck  bool   // type-check the generated code before writing
+
+	include, exclude *Filter           // operation filters
+	tagMode          TagMode           // assigns the operations to the services
+	operationNames   map[string]string // operation ID, or HTTP method and path to the method name
+	keepGetPrefix    bool              // do not trim the "Get" prefix of the method names
+	initialisms      map[string]bool   // additional initialisms of the Go identifiers, see WithInitialisms
+	typeMappings     map[string]string // schema type to the Go type
+	mappedTypes      map[string]*mappedType
+	fileImports      map[string]externalPackage // external packages used by the current file
+
+	opNames    map[*ir.Operation]string // operation to the resolved method name
+	namespaces map[string]*namespace    // scope to the identifiers
+	renames    []*Rename                // renamed identifiers
+	diags      diag.List                // warnings of the generation, see warnf
+	loadDiags  diag.List                // warnings of the OpenAPI 3.1 schema conversion, kept across the generations
+	logger     *slog.Logger             // logger of the progress, see WithLogger
+
+	patterns        map[string]string  // regexp pattern to variable name
+	pendingPatterns []string           // regexp patterns which are not declared yet in the current file
+	badPatterns     map[[2]string]bool // schema pointer and regexp pattern which Go regexp can not compile, warned once
+	sources         map[string]string  // Go declaration to the JSON pointer of the spec location, see declareSource
+}
+
+// Option configures the Generator.
+type Option func(*Generator)
+
+// WithServer generates the net/http server interface and router in addition to the client.
+func WithServer() Option {
+	return func(g *Generator) {
+		g.server = true
+	}
+}
+
+// WithInterfaces generates the per service interfaces such as PetsAPI, which the concrete service satisfies.
+func WithInterfaces() Option {
+	return func(g *Generator) {
+		g.interfaces = true
 	}
+}
 
-	f, err := os.Open(filename)
-	if err != nil {
-		return unknownSchema, err
+// WithoutClient does not generate the API client, such as generating the server only.
+func WithoutClient() Option {
+	return func(g *Generator) {
+		g.skipClient = true
 	}
-	defer f.Close()
+}
 
-	scan := bufio.NewScanner(f)
-	for scan.Scan() {
-		switch {
-		case strings.Contains(scan.Text(), SchemaNameSwagger):
-			return swaggerSchema, nil
-		case strings.Contains(scan.Text(), SchemaNameOpenAPI):
-			return openAPISchema, nil
-		}
+// WithoutModels does not generate the models. It is only valid with WithoutClient and without WithServer.
+func WithoutModels() Option {
+	return func(g *Generator) {
+		g.skipModels = true
 	}
-	if err := scan.Err(); err != nil {
-		return unknownSchema, err
+}
+
+// WithFake generates the fake subpackage which provides the in-memory fake of the API for testing.
+//
+// importPath is the import path of the generated package. WithFake implies WithInterfaces.
+func WithFake(importPath string) Option {
+	return func(g *Generator) {
+		g.interfaces = true
+		g.fake = importPath
 	}
+}
 
-	return unknownSchema, errors.New("unknown schema")
+// WithPackageName sets the package name of the generated code. The default is "api".
+func WithPackageName(name string) Option {
+	return func(g *Generator) {
+		g.pkgName = name
+	}
 }
 
-type Service struct {
-	Name string
-	tags openapi3.Tags
+// WithSchemaType sets the schema type of the schema read by New or NewFromReader, one of (openapi, swagger).
+//
+// The schema type overrides the detection by DetectSchemaType, which is used if name is empty or unknown.
+func WithSchemaType(name string) Option {
+	return func(g *Generator) {
+		g.schemaType = schemaTypeMap[strings.ToLower(name)]
+	}
 }
 
-type Services []*Service
+// defaultPackageName is the package name of the generated code if not given by WithPackageName.
+const defaultPackageName = "api"
 
-// Generator represents a Go source generator from OpenAPI.
-type Generator struct {
-	openAPI    *openapi3.T
-	schemaType schemaType
-	pkgName    string
+// New parses the JSON or YAML schema file and returns the new Generator.
+//
+// schemaType is the same as WithSchemaType, and pkgName is the same as WithPackageName.
+func New(schemaType, pkgName, filename string, opts ...Option) (*Generator, error) {
+	// handle path arg
+	switch fi, err := os.Stat(filename); {
+	case os.IsNotExist(err):
+		return nil, fmt.Errorf("not exists %s schema file", filename)
 
-	buf   *bytes.Buffer
-	files map[string][]byte
+	case err != nil:
+		return nil, err
 
-	services     Services                  // lazy initialize
-	servicesOnce sync.Once                 // run GetService once
-	methods      map[*Service]PathItemsMap // lazy initialize
-	methodsOnce  sync.Once                 // run GetMethods once
+	case fi.IsDir():
+		return nil, fmt.Errorf("%s is directory, not schema file", filename)
+	}
 
-	p  printFn // print raw
-	pp printFn // print with newline
+	g, err := newGenerator(append([]Option{WithSchemaType(schemaType), WithPackageName(pkgName)}, opts...)...)
+	if err != nil {
+		return nil, err
+	}
+	g.filename = filename
+
+	f, err := os.Open(filename)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+
+	if err := g.load(f); err != nil {
+		return nil, fmt.Errorf("failed to load %s: %w", filename, err)
+	}
+
+	return g, nil
 }
 
-// New parses path JSON file and returns the new Generator.
-func New(schemaType, pkgName, filename string) (*Generator, error) {
-	st, err := parseSchemaType(schemaType, filename)
+// NewFromReader parses the JSON or YAML schema read from r and returns the new Generator.
+func NewFromReader(r io.Reader, opts ...Option) (*Generator, error) {
+	g, err := newGenerator(opts...)
 	if err != nil {
 		return nil, err
 	}
-	g := &Generator{
-		schemaType: st,
-		pkgName:    pkgName,
-		buf:        new(bytes.Buffer),
-		files:      make(map[string][]byte),
+	if err := g.load(r); err != nil {
+		return nil, err
 	}
-	g.p = func(format string, args ...interface{}) {
-		_, err := fmt.Fprintf(g.buf, format, args...)
-		if err != nil {
-			panic(err)
-		}
+
+	return g, nil
+}
+
+// NewFromDocument returns the new Generator of the loaded OpenAPI document.
+//
+// The Generator may modify doc, such as removing the operations which are not selected by WithFilter.
+func NewFromDocument(doc *openapi3.T, opts ...Option) (*Generator, error) {
+	if doc == nil {
+		return nil, errors.New("nil OpenAPI document")
 	}
-	g.pp = func(format string, args ...interface{}) {
-		g.p(format+"\n", args...)
+
+	g, err := newGenerator(opts...)
+	if err != nil {
+		return nil, err
 	}
+	g.schemaType = openAPISchema
+	g.openAPI = doc
 
-	// handle path arg
-	switch fi, err := os.Stat(filename); {
-	case os.IsNotExist(err):
-		return nil, fmt.Errorf("not exists %s schema file", filename)
+	return g, nil
+}
 
-	case fi.IsDir():
-		return nil, fmt.Errorf("%s is directory, not schema file", filename)
+// newGenerator returns the new Generator configured by opts, which has no schema yet.
+func newGenerator(opts ...Option) (*Generator, error) {
+	g := &Generator{
+		pkgName:    defaultPackageName,
+		namespaces: make(map[string]*namespace),
+		logger:     discardLogger,
 
-	case err != nil:
+		operationNames: make(map[string]string),
+		typeMappings:   make(map[string]string),
+		fileImports:    make(map[string]externalPackage),
+	}
+	for _, opt := range opts {
+		opt(g)
+	}
+	if err := g.parseTypeMappings(); err != nil {
+		return nil, err
+	}
+	switch {
+	case g.skipClient && (g.interfaces || g.fake != ""):
+		return nil, errors.New("interfaces and fake require the client")
+	case g.skipModels && (!g.skipClient || g.server):
+		return nil, errors.New("client and server require the models")
+	}
+	if err := g.parseTemplates(); err != nil {
 		return nil, err
 	}
 
-	buf, err := os.ReadFile(filename)
+	return g, nil
+}
+
+// load reads the schema from r, and converts to the OpenAPI 3.0 document if the Swagger or OpenAPI 3.1 schema.
+func (g *Generator) load(r io.Reader) error {
+	buf, err := io.ReadAll(r)
 	if err != nil {
-		return nil, err
+		return fmt.Errorf("failed to read schema: %w", err)
+	}
+	g.source = buf
+
+	if g.schemaType == unknownSchema {
+		st, err := detectSchemaType(buf)
+		if err != nil {
+			return err
+		}
+		g.schemaType = st
+	}
+
+	// converts the OpenAPI 3.1 schema to 3.0, which is decoded as the OpenAPI 3.0 schema
+	if g.schemaType == openAPISchema && IsOpenAPI31(buf) {
+		if buf, g.loadDiags, err = openapi31.Convert(buf); err != nil {
+			return err
+		}
+		g.logger.Debug("converted OpenAPI 3.1 schema to 3.0", "warnings", len(g.loadDiags))
+	}
+
+	// decodes the YAML schema as JSON
+	if !json.Valid(buf) {
+		if buf, err = yaml.YAMLToJSON(buf); err != nil {
+			return fmt.Errorf("failed to convert YAML schema to JSON: %w", err)
+		}
 	}
 
 	dec := json.NewDecoder(bytes.NewReader(buf))
@@ -185,7 +320,7 @@ func New(schemaType, pkgName, filename string) (*Generator, error) {
 	case openAPISchema:
 		var oai openapi3.T
 		if err := dec.Decode(&oai); err != nil {
-			return nil, fmt.Errorf("failed to decode %s: %w", filename, err)
+			return fmt.Errorf("failed to decode OpenAPI schema: %w", err)
 		}
 
 		g.openAPI = &oai
@@ -193,21 +328,30 @@ func New(schemaType, pkgName, filename string) (*Generator, error) {
 	case swaggerSchema:
 		var swagger openapi2.T
 		if err := dec.Decode(&swagger); err != nil {
-			return nil, err
+			return fmt.Errorf("failed to decode Swagger schema: %w", err)
 		}
 
 		oai, err := openapi2conv.ToV3(&swagger)
 		if err != nil {
-			return nil, fmt.Errorf("failed to convert %#v to OpenAPI schema: %w", swagger, err)
+			return fmt.Errorf("failed to convert %#v to OpenAPI schema: %w", swagger, err)
 		}
 
 		g.openAPI = oai
 	}
 
-	return g, nil
+	return nil
 }
 
-// Generate generates the API from openapi3.T schema.
+// Document returns the loaded OpenAPI document.
+//
+// The Swagger and OpenAPI 3.1 schemas are returned as converted to the OpenAPI 3.0 document.
+func (g *Generator) Document() *openapi3.T {
+	return g.openAPI
+}
+
+// Generate generates the API from openapi3.T schema, and writes the files under dst.
+//
+// dst is the current directory if empty.
 func (g *Generator) Generate(dst string) (err error) {
 	if dst == "" {
 		dst, err = os.Getwd()
@@ -220,25 +364,24 @@ func (g *Generator) Generate(dst string) (err error) {
 		return fmt.Errorf("failed to generate: %w", err)
 	}
 
+	// finds the stale files before overwriting the manifest
+	var stale []string
+	if g.clean {
+		stale, err = g.staleFiles(dst)
+		if err != nil {
+			return err
+		}
+	}
+
 	if err := os.MkdirAll(dst, 0755); err != nil {
 		return fmt.Errorf("failed to MkdirAll %s: %w", dst, err)
 	}
-
-	for filename, data := range g.files {
-		f, err := os.Create(filepath.Join(dst, filename))
-		if err != nil {
-			return fmt.Errorf("failed to Create %s: %w", f.Name(), err)
-		}
-		if _, err = f.Write(data); err != nil {
-			return fmt.Errorf("failed to Write to %s: %w", f.Name(), err)
-		}
-		// should not use defer
-		if err := f.Close(); err != nil {
-			return fmt.Errorf("failed to Close to %s: %w", f.Name(), err)
-		}
+	if err := g.writeFiles(DirWriter(dst)); err != nil {
+		return err
 	}
+	g.logger.Info("wrote generated files", "dir", dst, "files", len(g.files))
 
-	return nil
+	return g.removeStaleFiles(dst, stale)
 }
 
 func fileName(prefix, name string) string {
@@ -252,402 +395,345 @@ func fileName(prefix, name string) string {
 
 // generate generates Go source code from OpenAPI spec.
 //
-// It works sequential, does not needs mutex lock.
-func (g *Generator) generate() error {
-	// writes doc.go
-	g.WriteHeader()
-	g.p("\n")
-	g.WriteDoc()
-	bufDoc := g.buf.Bytes()
-	doc, err := goformat.Source(bufDoc)
+// It works sequential, does not needs mutex lock. The diagnostics of the returned error are located in the schema.
+func (g *Generator) generate() (err error) {
+	defer func() { g.locateError(err) }()
+
+	g.files = make(map[string][]byte)
+	g.patterns, g.pendingPatterns, g.badPatterns = nil, nil, nil
+	g.sources, g.diags = nil, nil
+	g.namespaces, g.renames = make(map[string]*namespace), nil
+
+	api, err := g.API()
 	if err != nil {
-		return fmt.Errorf("could not format doc.go: %w\n%s", err, string(bufDoc))
+		return err
+	}
+	g.api = api
+	g.logger.Debug("built API", "services", len(api.Services), "operations", len(api.Operations), "models", len(api.Models))
+	if err := g.resolveOperationNames(); err != nil {
+		return err
+	}
+	g.resolveNames()
+
+	// declares the services first, the client refers those constructors
+	var services []*ServiceData
+	if !g.skipClient {
+		for _, svc := range g.api.Services {
+			services = append(services, g.newServiceData(svc))
+		}
+	}
+
+	// writes doc.go
+	if err := g.render(docFileName, docTemplate, g.newFileData()); err != nil {
+		return err
 	}
-	g.files[docFileName] = doc
 
 	// writes client.go
-	g.buf.Reset()
-	g.WriteHeader()
-	g.p("\n")
-	g.WritePackage()
-	g.p("\n")
-	g.WriteImports()
-	g.p("\n")
-	g.WriteConstants()
-	g.p("\n")
-	g.WriteService()
-	g.WriteSchemaDescriptor()
-
-	b := g.buf.Bytes()
-	client, err := goformat.Source(b)
+	client, err := g.buildClient(services)
 	if err != nil {
-		return fmt.Errorf("could not format client.go: %w\n%s", err, string(b))
+		return err
+	}
+	file := g.newFileData()
+	file.Client = client
+	if err := g.render(clientFileName, clientTemplate, file); err != nil {
+		return err
 	}
-	g.files[clientFileName] = client
 
 	// writes api_xxx.go
-	for _, tag := range g.GetService() {
-		g.buf.Reset()
-		fmt.Printf("tag: %#v\n", tag)
-		g.WriteHeader()
-		g.p("\n")
-		g.WritePackage()
-		g.p("\n")
-		g.WriteImports()
-		g.p("\n")
-		g.WriteAPI(tag)
-
-		bufAPI := g.buf.Bytes()
-		api, err := goformat.Source(bufAPI)
-		if err != nil {
-			return fmt.Errorf("could not format %s api: %w\n%s", tag.Name, err, string(bufAPI))
+	if !g.skipClient {
+		for i, service := range g.api.Services {
+			svc := services[i]
+			g.buildOperations(svc, service)
+
+			file := g.newFileData()
+			file.Service = svc
+			file.Patterns = g.takePatterns()
+			if err := g.render(fileName("api", svc.Name), apiTemplate, file); err != nil {
+				return err
+			}
 		}
-		g.files[fileName("api", tag.Name)] = api
 	}
 
-	// sorts schemas alphabetically by those names
-	schemas := make([]string, len(g.openAPI.Components.Schemas))
-	i := 0
-	for name := range g.openAPI.Components.Schemas {
-		schemas[i] = name
-		i++
+	// writes models sorted by names
+	if !g.skipModels {
+		for _, m := range g.api.Models {
+			// builds the model first to collect the imports of the mapped types
+			g.fileImports = make(map[string]externalPackage)
+			model := g.buildModel(m)
+
+			file := g.newFileData(g.importedPackages()...)
+			file.Model = model
+			file.Patterns = g.takePatterns()
+			name := fileName("model", m.Name)
+			g.declareSource(name, modelPointer(m.Name))
+			if err := g.render(name, modelTemplate, file); err != nil {
+				return err
+			}
+		}
 	}
-	sort.Strings(schemas)
 
-	// writes models sorted by names
-	for _, name := range schemas {
-		g.buf.Reset()
-		ref := g.openAPI.Components.Schemas[name]
-		g.WriteHeader()
-		g.p("\n")
-		g.WritePackage()
-		g.p("\n")
-		g.WriteModel(name, ref, g.openAPI.Components.Schemas)
-
-		bufModel := g.buf.Bytes()
-		model, err := goformat.Source(bufModel)
-		if err != nil {
-			return fmt.Errorf("could not format %s model: %w\n%s", name, err, string(bufModel))
+	// writes server.go
+	if g.server {
+		file := g.newFileData(externalPackage{pkg: middlewarePkg})
+		file.Server = g.buildServer()
+		if err := g.render(serverFileName, serverTemplate, file); err != nil {
+			return err
+		}
+	}
+
+	// writes webhooks.go
+	if g.generatesWebhooks() {
+		file := g.newFileData()
+		file.Webhooks = g.buildWebhooks()
+		if err := g.render(webhooksFileName, webhooksTemplate, file); err != nil {
+			return err
+		}
+	}
+
+	// writes fake/fake.go
+	if g.fake != "" {
+		file := g.newFileData()
+		file.Fake = g.buildFake(services)
+		if err := g.render(fakeFileName, fakeTemplate, file); err != nil {
+			return err
 		}
-		g.files[fileName("model", name)] = model
 	}
 
 	// writes utils.go
-	g.buf.Reset()
-	g.WriteHeader()
-	g.p("\n")
-	g.WritePackage()
-	g.p("\n")
-	g.WriteImports()
-	g.p("\n")
-
-	bufUtils := g.buf.Bytes()
-	utils, err := goformat.Source(bufUtils)
-	if err != nil {
-		return fmt.Errorf("could not format utils.go: %w\n%s", err, string(bufUtils))
+	if err := g.render(utilsFileName, utilsTemplate, g.newFileData()); err != nil {
+		return err
+	}
+
+	g.writeManifest()
+
+	if g.typeCheck {
+		return g.checkTypes()
 	}
-	g.files[utilsFileName] = utils
 
 	return nil
 }
 
-// GetService gets sorted openapi3.Tags, sorted by openapi3.Tag.Name.
-func (g *Generator) GetService() Services {
-	g.servicesOnce.Do(func() {
-		i := 0
-		for methods := range g.GetMethods() {
-			if g.services == nil { // lazy initialize
-				g.services = make(Services, len(g.methods))
-			}
-			g.services[i] = methods
-			i++
-		}
+// API applies the filters and returns the intermediate representation of the API, which the generated code is
+// built from.
+func (g *Generator) API() (*ir.API, error) {
+	g.applyFilter()
 
-		sort.SliceStable(g.services, func(i, j int) bool { return g.services[i].Name < g.services[j].Name })
-	})
+	api, err := ir.Build(g.openAPI, ir.WithServiceName(serviceName), ir.WithOperationServices(g.operationServices))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build API: %w", err)
+	}
 
-	return g.services
+	return api, nil
 }
 
-var defaultService = &Service{Name: "default"}
-
-// GetMethods gets services method from the parses openapi3.Tags.
-func (g *Generator) GetMethods() map[*Service]PathItemsMap {
-	g.methodsOnce.Do(func() {
-		g.methods = make(map[*Service]PathItemsMap)
+const headerFmt = `// Code generated by github.com/zchee/go-openapi-tools/cmd/oapi-generator. DO NOT EDIT.`
 
-		switch len(g.openAPI.Tags) {
-		case 0:
-			g.methods[defaultService] = make(map[string][]*openapi3.PathItem)
+// middlewarePkg is the import path of the validation middleware package.
+const middlewarePkg = "github.com/zchee/go-openapi-tools/middleware"
 
-		default:
-			// serviceMap := make(map[string]*Service)
-			//
-			// // initialize a.methods map keys to *openapi3.Tag
-			// for _, tag := range g.openAPI.Tags {
-			// 	if idx := strings.Index(tag.Name, "."); idx > -1 {
-			// 		tag.Name = tag.Name[:idx]
-			// 	}
-			//
-			// 	if svc, ok := serviceMap[tag.Name]; ok {
-			// 		svc.tags = append(svc.tags, tag)
-			// 		serviceMap[tag.Name] = svc
-			// 		continue
-			// 	}
-			//
-			// 	serviceMap[tag.Name] = &Service{
-			// 		Name: tag.Name,
-			// 		tags: openapi3.Tags{tag},
-			// 	}
-			// }
-			//
-			// for _, svc := range serviceMap {
-			// 	g.methods[svc] = make(map[string][]*openapi3.PathItem)
-			// }
-
-			for _, tag := range g.openAPI.Tags {
-				tag.Name = strings.Trim(strings.ReplaceAll(tag.Name, " ", ""), " (apps)")
-				s := &Service{
-					Name: tag.Name,
-					tags: openapi3.Tags{tag},
-				}
-				g.methods[s] = make(map[string][]*openapi3.PathItem)
-			}
-		}
+type externalPackage struct {
+	pkg   string
+	alias string
+}
 
-		// makes a.methods
-		for path, item := range g.openAPI.Paths {
-			for _, op := range item.Operations() {
-				switch len(op.Tags) {
-				case 0:
-					fmt.Fprintf(os.Stderr, "path: %T = %#v, item: %T = %#v\n", path, path, item, item)
-					g.methods[defaultService][path] = append(g.methods[defaultService][path], item)
-				case 1:
-					// handles multiple tags
-					// a.methods map keys are *openapi3.Tag, get actual tag name and compare op.Tags[n]
-					for s := range g.methods {
-						// append *openapi3.PathItem
-						//  s: *openapi3.Tag
-						//  path: path
-						//  item: *openapi3.PathItem
-						g.methods[s][path] = append(g.methods[s][path], item)
-					}
-				}
-			}
-		}
-	})
+// newFileData returns the template data of the generated file which imports the external packages.
+func (g *Generator) newFileData(extPkgs ...externalPackage) *FileData {
+	file := &FileData{
+		Header:  headerFmt,
+		Package: g.pkgName,
+		Title:   g.depunct(g.pkgName, true),
+	}
+	for _, ext := range extPkgs {
+		file.Imports = append(file.Imports, &ImportData{Path: ext.pkg, Alias: ext.alias})
+	}
 
-	return g.methods
+	return file
 }
 
-const headerFmt = `// Code generated by github.com/zchee/go-openapi-tools/cmd/oapi-generator. DO NOT EDIT.`
+// sentence returns s lower cased and ended with dot, or empty if s is empty.
+func sentence(s string) string {
+	s = strings.ToLower(s)
+	if s != "" && s[len(s)-1] != '.' {
+		s += "."
+	}
 
-// WriteHeader writes license and any file headers.
-func (g *Generator) WriteHeader() {
-	g.pp(headerFmt)
+	return s
 }
 
-// WriteDoc writes package top level synopsis.
-func (g *Generator) WriteDoc() {
-	g.pp("// Package %s provides access to the %s REST API.", g.pkgName, Depunct(g.pkgName, true))
-	g.WritePackage()
-}
+// article returns the indefinite article of the word.
+func article(word string) string {
+	if word != "" && IsVowel(rune(word[0])) {
+		return "an"
+	}
 
-// WritePackage writes package statement.
-func (g *Generator) WritePackage() {
-	g.pp("package %s", g.pkgName)
+	return "a"
 }
 
-type externalPackage struct {
-	pkg   string
-	alias string
-}
+// buildClient returns the template data of the API client, which has the services.
+func (g *Generator) buildClient(services []*ServiceData) (*ClientData, error) {
+	client := &ClientData{
+		Version:  g.api.Version,
+		Client:   !g.skipClient,
+		Services: services,
+	}
+	switch len(g.api.Servers) {
+	case 0:
+		client.BasePath, client.HasBasePath = "/", true
+	case 1:
+		client.BasePath, client.HasBasePath = g.api.Servers[0], true
+	}
 
-// WriteImports writes import section.
-func (g *Generator) WriteImports() {
-	g.pp("import (")
-
-	// write std packages
-	pkgs := []string{
-		"bytes",
-		"compress/gzip",
-		"context",
-		"encoding/json",
-		"errors",
-		"fmt",
-		"io",
-		"io/ioutil",
-		"net/http",
-		"net/url",
-		"path",
-		"strconv",
-		"strings",
-	}
-	for _, pkg := range pkgs {
-		g.pp("	%q", pkg)
-	}
-
-	g.p("\n")
-
-	// write external packages
-	// extPkgs := []externalPackage{
-	// 	{
-	// 		pkg:   "google.golang.org/api/transport/http",
-	// 		alias: "htransport",
-	// 	},
-	// }
-	// for _, ext := range extPkgs {
-	// 	g.pp("	%s %q", ext.alias, ext.pkg)
-	// }
-	g.pp(")")
-
-	g.p("\n")
-
-	// write keep imported package pragma
-	g.pp("// Always reference these packages, just in case the auto-generated code below doesn't.")
-	g.pp("var (")
-	g.pp("	_ = bytes.NewBuffer")
-	g.pp("	_ = context.Canceled")
-	g.pp("	_ = json.NewDecoder")
-	g.pp("	_ = errors.New")
-	g.pp("	_ = fmt.Sprintf")
-	g.pp("	_ = io.Copy")
-	g.pp("	_ = ioutil.ReadAll")
-	g.pp("	_ = http.NewRequest")
-	g.pp("	_ = url.Parse")
-	g.pp("	_ = strconv.Itoa")
-	g.pp("	_ = path.Join")
-	g.pp("	_ = strings.Replace")
-	g.pp("	_ = gzip.NewReader")
-	g.pp(")")
+	// embeds gzipped compressed and JSON marshaled schema spec
+	data, err := json.Marshal(g.openAPI)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	buf := &bytes.Buffer{}
+	zw, err := gzip.NewWriterLevel(buf, gzip.BestCompression)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compress schema: %w", err)
+	}
+	if _, err := zw.Write(data); err != nil {
+		return nil, fmt.Errorf("failed to compress schema: %w", err)
+	}
+	if err := zw.Close(); err != nil {
+		return nil, fmt.Errorf("failed to compress schema: %w", err)
+	}
+
+	b := buf.Bytes()
+	client.Size = len(b)
+	for len(b) > 0 {
+		n := 16
+		if n > len(b) {
+			n = len(b)
+		}
+
+		s := ""
+		for _, c := range b[:n] {
+			s += fmt.Sprintf("0x%02x, ", c)
+		}
+		client.Descriptor = append(client.Descriptor, s)
+
+		b = b[n:]
+	}
+
+	return client, nil
 }
 
-// WriteConstants writes constants.
-func (g *Generator) WriteConstants() {
-	version := g.openAPI.Info.Version
+// newServiceData returns the template data of the child service, which has no operations yet.
+func (g *Generator) newServiceData(service *ir.Service) *ServiceData {
+	svcName := g.serviceType(service)
+	svc := &ServiceData{
+		Name:        svcName,
+		Constructor: g.declName("constructor "+svcName, "New"+svcName),
+	}
 
-	// exported fields
-	g.pp("const (")
-	g.pp("	APIVersion = %q", version)
-	g.pp("	UserAgent = \"oaigen/\" + APIVersion")
-	g.pp(")")
+	// service description, if any
+	if len(service.Tags) > 0 {
+		if description := sentence(serviceName(service.Tags[0])); description != "" {
+			svc.Doc = svcName + " represents " + article(description) + " " + description
+		}
+	}
 
-	g.p("\n")
+	return svc
+}
 
-	// unexported fields
-	g.pp("const (")
-	switch len(g.openAPI.Servers) {
-	case 0:
-		g.pp("	basePath = %q", "/")
-	case 1:
-		g.pp("	basePath = %q", g.openAPI.Servers[0].URL)
+// headerConvMap is the map of header schema type to Go type and parse expression format.
+var headerConvMap = map[string][2]string{
+	"integer": {"int", "strconv.Atoi(%s)"},
+	"number":  {"float64", "strconv.ParseFloat(%s, 64)"},
+	"boolean": {"bool", "strconv.ParseBool(%s)"},
+}
+
+// buildResponseHeaders returns the typed accessors of the response headers declared in the spec.
+func (g *Generator) buildResponseHeaders(respType string, headers []*ir.Header) []*HeaderData {
+	var accessors []*HeaderData
+	for _, hdr := range headers {
+		accessor := &HeaderData{
+			Name:   hdr.Name,
+			Method: g.responseField(respType, "header "+hdr.Name, g.depunct(hdr.Name, true)+"Header"),
+			Type:   "string",
+		}
+
+		typ := "string"
+		if t := hdr.Type.Resolve(); t != nil {
+			typ = schemaTypeName(t)
+		}
+		if conv, ok := headerConvMap[typ]; ok {
+			accessor.Type = conv[0]
+			accessor.Parse = fmt.Sprintf(conv[1], "values[0]")
+		}
+		accessors = append(accessors, accessor)
 	}
-	g.pp(")")
+
+	return accessors
 }
 
-// WriteService writes API Service struct and New function.
-func (g *Generator) WriteService() {
-	var serviceNames []string // for cache sorted service names
-
-	// write Service struct
-	g.pp("// Service represents a %ss.", Depunct(g.pkgName, true)+" Service")
-	g.pp("type Service struct {")
-	g.pp("	client *http.Client")
-	g.pp("	BasePath string // API endpoint base URL")
-	g.pp("	UserAgent string // optional additional User-Agent fragment")
-	g.p("\n")
-	for i, tag := range g.GetService() {
-		if serviceNames == nil {
-			serviceNames = make([]string, len(g.services)) // lazy initialize
+// successResponse returns the first success (2xx) response of op, or nil if none.
+func successResponse(op *ir.Operation) *ir.Response {
+	for _, resp := range op.Responses {
+		if len(resp.Code) == 3 && resp.Code[0] == '2' {
+			return resp // sorted by the status code
 		}
-		svcName := Depunct(tag.Name, true)
-		g.pp("	%[1]s *%[1]s", svcName)
-		serviceNames[i] = svcName
-	}
-	g.pp("}")
-
-	// write NewService function
-	g.pp("// NewService creates a new %s.", Depunct(g.pkgName, true)+" Service")
-	g.pp("func NewService(ctx context.Context) (*Service, error) {")
-	g.pp("	client := &http.Client{}")
-	g.pp("	svc := &Service{client: client, BasePath: basePath}")
-	for _, svcName := range serviceNames {
-		g.pp("	svc.%[1]s = New%[1]s(svc)", svcName)
-	}
-	g.p("\n")
-	g.pp("	return svc, nil")
-	g.pp("}")
-
-	g.p("\n")
-
-	// write userAgent method
-	g.pp("func (s *Service) userAgent() string {")
-	g.pp("	if s.UserAgent == \"\" { return UserAgent }")
-	g.pp("	return UserAgent + \" \" + s.UserAgent")
-	g.pp("}")
+	}
+
+	return nil
 }
 
 // https://github.com/swagger-api/swagger-codegen/blob/99673744630a/modules/swagger-codegen/src/main/java/io/swagger/codegen/languages/AbstractGoCodegen.java#L62-L80
 // https://github.com/OpenAPITools/openapi-generator/blob/19acd36e3af1/modules/openapi-generator/src/main/java/org/openapitools/codegen/languages/AbstractGoCodegen.java#L101-L118
 var typeConvMap = map[string]string{
-	"integer":    "int32",
-	"long":       "int64",
-	"number":     "float32",
-	"float":      "float32",
-	"double":     "float64",
-	"BigDecimal": "float64",
-	"boolean":    "bool",
-	"string":     "string",
-	"UUID":       "string",
-	"URI":        "string",
-	"date":       "string",
-	"DateTime":   "time.Time",
-	"password":   "string",
-	"File":       "*os.File",
-	"file":       "*os.File",
-	"binary":     "string",
-	"ByteArray":  "string",
-	"array":      "interface{}",            // TODO(zchee): parse actual type
-	"object":     "map[string]interface{}", // TODO(zchee): parse actual type
+	"integer": "int32",
+	"number":  "float32",
+	"boolean": "bool",
+	"string":  "string",
+	"array":   "interface{}",            // TODO(zchee): parse actual type
+	"object":  "map[string]interface{}", // TODO(zchee): parse actual type
 }
 
-// WriteAPI writes child API service structs and New(Service) function.
-func (g *Generator) WriteAPI(tag *Service) {
-	svcName := Depunct(tag.Name, true)
-
-	// writes service description, if any
-	if tag.tags != nil {
-		if description := strings.ToLower(tag.tags.Get(tag.Name).Name); description != "" {
-			// add dot if description is not end to dot
-			if description[len(description)-1] != '.' {
-				description += "."
-			}
+// formatConvMap is the map of the schema type and format to the Go type, which takes precedence over typeConvMap.
+//
+// The other formats such as "date-time" are the string which is validated by the Validate method, WithTypeMappings
+// maps them to the other Go types such as time.Time.
+var formatConvMap = map[string]string{
+	"integer:int32": "int32",
+	"integer:int64": "int64",
+	"number:float":  "float32",
+	"number:double": "float64",
+}
 
-			g.p("// %s represents ", svcName)
-			g.p("a")
-			// add 'n' if first letter of description is vowel
-			if IsVowel(rune(description[0])) {
-				g.p("n")
-			}
-			g.pp(" %s", description)
-		}
+// primitiveType returns the Go type of the schema type and format of t.
+func primitiveType(t *ir.Type) (string, bool) {
+	name := schemaTypeName(t)
+	if typ, ok := formatConvMap[name+":"+t.Format]; ok {
+		return typ, true
 	}
+	typ, ok := typeConvMap[name]
 
-	// write service struct
-	g.pp("type %s struct {", svcName)
-	g.pp("	s *Service")
-	g.pp("}")
-
-	// write NewXXX function
-	g.pp("// New%[1]s returns the new %[1]s.", svcName)
-	g.pp("func New%[1]s(s *Service) *%[1]s {", svcName)
-	g.pp("	rs := &%s{s: s}", svcName)
-	g.pp("	return rs")
-	g.pp("}")
+	return typ, ok
+}
 
-	g.p("\n")
+// schemaTypeName returns the schema type name of t, such as "string", "array" and "object".
+func schemaTypeName(t *ir.Type) string {
+	switch t.Kind {
+	case ir.Primitive, ir.Enum:
+		return t.Primitive
+	case ir.Slice:
+		return "array"
+	case ir.Struct, ir.Map:
+		return "object"
+	default:
+		return ""
+	}
+}
 
-	g.WriteMethods(svcName, tag)
+// paramType returns the Go type of the parameter, if the schema type is known.
+func paramType(param *ir.Param) (string, bool) {
+	t := param.Type.Resolve()
+	if t == nil {
+		return "", false
+	}
+	return primitiveType(t)
 }
 
 const (
@@ -656,574 +742,343 @@ const (
 	mimeJSON          = "application/json"
 )
 
-// WriteMethods writes child Service methods.
-func (g *Generator) WriteMethods(svcName string, service *Service) {
-	operations := make(map[string]map[string]*openapi3.Operation)
-	paths := make([]string, 0, len(g.methods[service]))
-	// http.MethodConnect | http.MethodDelete | http.MethodGet | http.MethodHead | http.MethodOptions | http.MethodPatch | http.MethodPost | http.MethodPut | http.MethodTrace
-	methods := make([]string, 0, 9)
-
-	for path, pathItems := range g.methods[service] {
-		paths = append(paths, path)
-		for _, item := range pathItems {
-			for method, op := range item.Operations() {
-				methods = append(methods, method)
-				op.OperationID = Depunct(op.OperationID, true)
-
-				// Go idiom
-				if op.OperationID != "Get" {
-					op.OperationID = strings.ReplaceAll(op.OperationID, "Get", "")
-				}
-
-				if operations[path] == nil {
-					operations[path] = make(map[string]*openapi3.Operation)
-				}
-				operations[path][method] = op
-			}
-		}
+// buildOperations builds the operations of the service, and the interface name if the interfaces are generated.
+func (g *Generator) buildOperations(svc *ServiceData, service *ir.Service) {
+	for _, op := range service.Operations {
+		svc.Operations = append(svc.Operations, g.buildOperation(svc.Name, op))
 	}
-	sort.Strings(paths)
-	sort.Strings(methods)
-
-	seen := make(map[string]bool)
-	for _, path := range paths {
-		for _, method := range methods {
-			op, ok := operations[path][method]
-			if ok {
-				methType := fmt.Sprintf("%s%sCall", svcName, op.OperationID)
-				if seen[methType] {
-					continue
-				}
 
-				pm := make(map[string]openapi3.Parameters, 4) // map["path"|"query"|"header"|"cookie"]openapi3.Parameters
-				for _, param := range op.Parameters {
-					switch param.Value.In {
-					case openapi3.ParameterInPath:
-						pm[openapi3.ParameterInPath] = append(pm[openapi3.ParameterInPath], param)
-					case openapi3.ParameterInQuery:
-						pm[openapi3.ParameterInQuery] = append(pm[openapi3.ParameterInQuery], param)
-					case openapi3.ParameterInHeader:
-						pm[openapi3.ParameterInHeader] = append(pm[openapi3.ParameterInHeader], param)
-					case openapi3.ParameterInCookie:
-						pm[openapi3.ParameterInCookie] = append(pm[openapi3.ParameterInCookie], param)
-					}
-				}
-
-				// sort by Parameter.Name
-				for _, in := range []string{openapi3.ParameterInPath, openapi3.ParameterInQuery, openapi3.ParameterInHeader, openapi3.ParameterInCookie} {
-					sort.SliceStable(pm[in], func(i, j int) bool { return pm[in][i].Value.Name < pm[in][j].Value.Name })
-				}
-
-				// sort params by path {xxx} order
-				pathParam := make([]*openapi3.ParameterRef, 0, len(pm[openapi3.ParameterInPath]))
-				pth := path
-				for {
-					idx := strings.Index(pth, "{")
-					if idx == -1 {
-						break
-					}
-					endIdx := strings.Index(pth[idx+1:], "}")
-					for _, param := range pm[openapi3.ParameterInPath] {
-						if pth[idx+1:idx+1+endIdx] == param.Value.Name {
-							pathParam = append(pathParam, param)
-						}
-					}
-					pth = pth[idx+endIdx:]
-				}
-
-				// writes operation summary, if any
-				if summary := strings.ToLower(op.Summary); summary != "" {
-					// add dot if summary is not end to dot
-					if summary[len(summary)-1] != '.' {
-						summary += "."
-					}
-
-					g.pp("// %s provides the %s", methType, summary)
-				}
+	if g.interfaces {
+		svc.Interface = g.declName("interface "+svc.Name, svc.Name+"API")
+	}
+}
 
-				// write service struct
-				g.pp("type %s struct {", methType)
-				g.pp("	s *Service")
-				g.pp("	header http.Header")
-				g.pp("	params url.Values")
-				g.p("\n")
-
-				// write path fields
-				if len(pathParam) > 0 {
-					g.pp("	// path fields")
-					for _, param := range pathParam {
-						paramName := NormalizeParam(Depunct(param.Value.Name, false))
-						paramType, ok := typeConvMap[param.Value.Schema.Value.Type]
-						if !ok {
-							continue
-						}
-
-						g.pp("	%s %s", paramName, paramType)
-					}
-				}
-				// write query fields
-				if len(pm[openapi3.ParameterInQuery]) > 0 {
-					g.pp("	// query fields")
-					for _, param := range pm[openapi3.ParameterInQuery] {
-						paramName := NormalizeParam(Depunct(param.Value.Name, false))
-						if param.Value.Schema == nil {
-							continue
-						}
-						paramType, ok := typeConvMap[param.Value.Schema.Value.Type]
-						if !ok {
-							continue
-						}
-
-						g.pp("	%s %s", paramName, paramType)
-					}
-				}
-				g.pp("}")
-				seen[methType] = true
-
-				g.p("\n")
-
-				pathItem := g.openAPI.Paths[path]
-				resp := pathItem.GetOperation(method).Responses["200"]
-				// fmt.Fprintf(os.Stderr, "resp.Value.Content: %#v\n", resp.Value.Content.Get("application/json").Schema.Value)
-				first := false
-				if resp != nil && resp.Value != nil && resp.Value.Content != nil {
-					g.p("type %sResponse struct {", methType)
-					for media := range resp.Value.Content {
-						content := resp.Value.Content.Get(media)
-						if content.Schema != nil && content.Schema.Value != nil && content.Schema.Value.Properties != nil {
-							for propName, prop := range resp.Value.Content.Get(media).Schema.Value.Properties {
-								if prop.Value == nil {
-									continue
-								}
-								omitempty := ""
-								if !contains(propName, resp.Value.Content.Get("application/json").Schema.Value.Required) {
-									omitempty = ",omitempty"
-								}
-								paramName := strcase.ToCamel(Depunct(propName, true))
-								paramType, ok := typeConvMap[prop.Value.Type]
-								if !ok {
-									continue
-								}
-
-								if !first {
-									g.p("\n")
-								}
-								first = true
-								g.pp("	%s %s `json:\"%s%s\"`", paramName, paramType, propName, omitempty)
-							}
-						}
-					}
-					g.pp("}")
-					g.p("\n")
-				}
+// buildOperation returns the template data of the operation of the svcName service.
+func (g *Generator) buildOperation(svcName string, op *ir.Operation) *OperationData {
+	opName := g.operationName(op)
+	methType := g.declName("call "+svcName+"."+opName, svcName+opName+"Call")
+	respType := g.declName("response "+svcName+"."+opName, methType+"Response")
+	g.declareSource(methType, operationPointer(op))
+	g.declareSource(respType, operationPointer(op))
+
+	o := &OperationData{
+		Name:         opName,
+		Service:      svcName,
+		CallType:     methType,
+		ResponseType: respType,
+		Summary:      sentence(op.Summary),
+		HTTPMethod:   "http.Method" + strcase.ToCamel(strings.ToLower(op.Method)),
+		Path:         op.Path,
+		BodyType:     g.requestBodyType(op),
+	}
 
-				// writes operation summary, if any
-				if summary := strings.ToLower(op.Summary); summary != "" {
-					// add dot if summary is not end to dot
-					if summary[len(summary)-1] != '.' {
-						summary += "."
-					}
+	// sort by Param.Name
+	pm := make(map[string][]*ir.Param, 4) // map["path"|"query"|"header"|"cookie"][]*ir.Param
+	for _, in := range []string{ir.InPath, ir.InQuery, ir.InHeader, ir.InCookie} {
+		pm[in] = op.ParamsIn(in)
+		sort.SliceStable(pm[in], func(i, j int) bool { return pm[in][i].Name < pm[in][j].Name })
+	}
 
-					g.pp("// %s returns the %s for %s", op.OperationID, methType, summary)
-				}
+	// sort params by path {xxx} order
+	pathParam := make([]*ir.Param, 0, len(pm[ir.InPath]))
+	pth := op.Path
+	for {
+		idx := strings.Index(pth, "{")
+		if idx == -1 {
+			break
+		}
+		endIdx := strings.Index(pth[idx+1:], "}")
+		for _, param := range pm[ir.InPath] {
+			if pth[idx+1:idx+1+endIdx] == param.Name {
+				pathParam = append(pathParam, param)
+			}
+		}
+		pth = pth[idx+endIdx:]
+	}
 
-				// write method
-				g.p("func (r *%s) %s(", svcName, op.OperationID)
-				if len(pathParam) > 0 {
-					for i, param := range pathParam {
-						g.p("%s %s", Depunct(param.Value.Name, false), typeConvMap[param.Value.Schema.Value.Type])
-						if i < len(pathParam)-1 {
-							g.p(", ")
-						}
-					}
-				}
-				g.pp(") *%s {", methType)
-				g.pp("	c := &%s{", methType)
-				g.pp("		s: r.s,")
-				g.pp("		header: make(http.Header),")
-				g.pp("		params: url.Values{},")
-				if len(pathParam) > 0 {
-					for _, param := range pathParam {
-						g.pp("		%[1]s: %[1]s,", Depunct(param.Value.Name, false))
-					}
+	args := make([]string, 0, len(pathParam)+1)
+	for _, param := range pathParam {
+		typ, ok := paramType(param)
+		if !ok {
+			continue
+		}
+		p := &ParamData{
+			Name:     param.Name,
+			In:       param.In,
+			Field:    g.callParam(methType, param),
+			Type:     typ,
+			Required: param.Required,
+		}
+		o.PathParams = append(o.PathParams, p)
+		args = append(args, p.Field+" "+p.Type)
+	}
+	for _, param := range pm[ir.InQuery] {
+		typ, ok := paramType(param)
+		if !ok {
+			continue
+		}
+		o.QueryParams = append(o.QueryParams, &ParamData{
+			Name:     param.Name,
+			In:       param.In,
+			Field:    g.callParam(methType, param),
+			Type:     typ,
+			Required: param.Required,
+		})
+	}
+	if o.BodyType != "" {
+		args = append(args, "body *"+o.BodyType)
+	}
+	o.Args = strings.Join(args, ", ")
+
+	resp := successResponse(op)
+	if resp != nil {
+		written := make(map[string]bool)
+		// the media types and properties are sorted, the fields must not depend on the map iteration order
+		for _, media := range resp.Content {
+			schema := media.Type
+			if schema == nil || schema.Kind != ir.Struct {
+				continue // the $ref schema has no inline properties
+			}
+			for _, prop := range schema.Fields {
+				if prop.Type == nil || prop.Type.Kind == ir.Ref || written[prop.Name] {
+					continue
 				}
-				g.pp("	}")
-				g.pp("	return c")
-				g.pp("}")
-
-				g.p("\n")
-
-				// write query method chains
-				for _, param := range pm[openapi3.ParameterInQuery] {
-					paramName := NormalizeParam(Depunct(param.Value.Name, false))
-					argName := Depunct(paramName, true)
-					typeName := paramName
-					if param.Value.Schema == nil {
-						continue
-					}
-					g.pp("func (c *%[1]s) %[2]s(%[3]s %[4]s) *%[1]s {", methType, argName, typeName, typeConvMap[param.Value.Schema.Value.Type])
-					g.pp("	c.params.Set(%[1]q, fmt.Sprintf(\"%%s\", %[1]s))", typeName)
-					g.pp("	return c")
-					g.pp("}")
-					g.p("\n")
+				fieldName := g.responseField(respType, prop.Name, strcase.ToCamel(g.depunct(prop.Name, true)))
+				fieldType, ok := primitiveType(prop.Type)
+				if !ok {
+					continue
 				}
 
-				g.p("\n")
+				written[prop.Name] = true
+				o.ResponseFields = append(o.ResponseFields, &FieldData{
+					Name:      fieldName,
+					Type:      fieldType,
+					JSON:      prop.Name,
+					OmitEmpty: !prop.Required,
+				})
+			}
+		}
+		o.ResponseHeaders = g.buildResponseHeaders(respType, resp.Headers)
+	}
 
-				// replace {xxx} in path
-				if len(pathParam) > 0 {
-					for _, param := range pathParam {
-						idx := strings.Index(path, "{")
-						if idx == -1 {
-							break
-						}
-						endIdx := strings.Index(path[idx+1:], "}")
+	// query setters after the fields, the setters must not take the field names
+	for _, p := range o.QueryParams {
+		p.Setter = g.callSetter(methType, &ir.Param{Name: p.Name, In: p.In})
+	}
 
-						path = path[:idx] + `" + ` + "fmt.Sprintf(\"%s\", c." + Depunct(param.Value.Name, false) + ")" + ` + "` + path[idx+1+endIdx+1:]
-					}
-				}
-				methodType := "http.Method" + strcase.ToCamel(strings.ToLower(method))
-
-				// write request
-				g.pp("// Do executes the %s.", svcName+op.OperationID)
-				g.pp("func (c *%s) Do(ctx context.Context) (*%sResponse, error) {", methType, methType)
-				g.pp("	uri := path.Join(c.s.BasePath, \"%s\")", path)
-				g.pp("	if len(c.params) > 0 {")
-				g.pp("		uri += \"?\" + c.params.Encode()")
-				g.pp("	}")
-				g.p("\n")
-				g.pp("	req, err := http.NewRequestWithContext(ctx, %s, uri, nil)", methodType)
-				g.pp("	if err != nil {")
-				g.pp("		return nil, err")
-				g.pp("	}")
-				g.p("\n")
-				g.pp("	req.Header.Set(%q, %q)", hdrContentType, mimeJSON)
-				g.pp("	req.Header.Set(%q, %q)", hdrAcceptEncoding, mimeJSON)
-				g.p("\n")
-				g.pp("	resp, err := c.s.client.Do(req)")
-				g.pp("	if err != nil {")
-				g.pp("		return nil, err")
-				g.pp("	}")
-				g.pp("	defer resp.Body.Close()")
-				g.p("\n")
-				g.pp("	if resp.StatusCode != 200 {")
-				g.pp("		return nil, errors.New(resp.Status)")
-				g.pp("	}")
-				g.p("\n")
-				g.pp("	body, err := io.ReadAll(resp.Body)")
-				g.pp("	if err != nil {")
-				g.pp("		return nil, err")
-				g.pp("	}")
-				g.p("\n")
-				g.pp("	var result %sResponse", methType)
-				g.pp("	if err := json.Unmarshal(body, &result); err != nil {")
-				g.pp("		return nil, err")
-				g.pp("	}")
-				g.p("\n")
-				g.pp("	return &result, nil")
-				g.pp("}\n")
-			}
+	bodyModel := ""
+	if schema := op.Body.JSON(); schema != nil && schema.Kind == ir.Ref {
+		bodyModel = o.BodyType
+	}
+	bodyRequired := o.BodyType != "" && op.Body.Required
+	o.Checks = g.callChecks(operationPointer(op), methType, pathParam, pm[ir.InQuery], bodyModel, bodyRequired)
+
+	// replace {xxx} in path
+	uriPath := op.Path
+	for _, param := range pathParam {
+		idx := strings.Index(uriPath, "{")
+		if idx == -1 {
+			break
 		}
+		endIdx := strings.Index(uriPath[idx+1:], "}")
+
+		uriPath = uriPath[:idx] + `" + ` + "fmt.Sprint(c." + g.callParam(methType, param) + ")" + ` + "` + uriPath[idx+1+endIdx+1:]
 	}
+	o.URI = strings.TrimSuffix(`"`+uriPath+`"`, ` + ""`)
+
+	return o
 }
 
-// WriteModel writes model definitions.
-func (g *Generator) WriteModel(modelName string, component *openapi3.SchemaRef, schemas openapi3.Schemas) {
-	if component.Value == nil && component.Value.Properties == nil {
-		return
+// buildModel returns the template data of the model.
+func (g *Generator) buildModel(m *ir.Model) *ModelData {
+	typeName := g.modelType(m.Name)
+	g.declareSource(typeName, modelPointer(m.Name))
+	model := &ModelData{
+		Name:     typeName,
+		Receiver: receiverName(typeName),
 	}
 
-	// sort propertyNames
-	propertyNames := make([]string, len(component.Value.Properties))
-	i := 0
-	for name := range component.Value.Properties {
-		propertyNames[i] = name
-		i++
+	desc := fmt.Sprintf("a model of %s.", g.depunct(m.Name, false))
+	if description := sentence(m.Type.Description); description != "" {
+		desc = article(description) + " " + description
 	}
-	sort.Strings(propertyNames)
-
-	g.p("// %s represents ", Depunct(modelName, true))
-	desc := fmt.Sprintf("a model of %s.", Depunct(modelName, false))
-	if description := strings.ToLower(component.Value.Description); description != "" {
-		// add dot if description is not end to dot
-		if description[len(description)-1] != '.' {
-			description += "."
-		}
+	model.Doc = typeName + " represents " + desc
 
-		desc = "a"
-		// add 'n' if first letter of description is vowel
-		if IsVowel(rune(description[0])) {
-			desc += "n"
-		}
-		desc += " " + strings.ReplaceAll(description, "\n", "\n// ")
+	if m.Type.Kind != ir.Struct {
+		g.buildTypeModel(model, m)
+		return model
 	}
-	g.pp(desc)
 
 	propertyTypes := make(map[string]string)
-	g.pp("type %s struct {", Depunct(modelName, true))
-	for _, name := range propertyNames {
-		property, ok := component.Value.Properties[name]
+	for _, property := range m.Type.Fields {
+		fieldName := g.modelField(m.Name, property.Name, g.depunct(property.Name, true))
+
+		typ, ok := g.propertyType(property.Type)
 		if !ok {
+			pointer := modelPointer(m.Name) + strings.TrimPrefix(diag.Pointer("properties", property.Name), "#")
+			if ref := unresolvedRef(property.Type); ref != "" {
+				g.warnf(pointer, "property %s of %s is skipped, $ref %s is not found", property.Name, m.Name, ref)
+			} else {
+				g.warnf(pointer, "property %s of %s is skipped, the schema has no Go type", property.Name, m.Name)
+			}
 			continue
 		}
-		omitempty := ""
-		if !contains(name, component.Value.Required) {
-			omitempty = ",omitempty"
-		}
+		propertyTypes[property.Name] = typ
+		model.Fields = append(model.Fields, &FieldData{
+			Name:      fieldName,
+			Type:      typ,
+			JSON:      property.Name,
+			OmitEmpty: !property.Required,
+		})
+	}
+	model.Checks = g.modelChecks(m.Name, m.Type, propertyTypes)
 
-		switch {
-		case property.Ref != "":
-			ref, ok := schemas[pathpkg.Base(property.Ref)]
-			if !ok {
-				fmt.Fprintf(os.Stderr, "property.Ref: %#v\n", property.Ref)
-				for name, schema := range schemas {
-					fmt.Fprintf(os.Stderr, "name: %s, schema: %#v\n", name, schema)
-				}
-			}
-			switch val := ref.Value; val.Type {
-			case "object":
-				if val.AdditionalProperties != nil && val.AdditionalProperties.Value != nil {
-					switch objVal := val.AdditionalProperties.Value; objVal.Type {
-					case "array":
-						if objVal.Items.Value != nil {
-							typ, ok := typeConvMap[objVal.Items.Value.Type]
-							if ok {
-								g.pp("	%s []%s `json:\"%s%s\"`", Depunct(name, true), typ, name, omitempty)
-								propertyTypes[name] = "[]" + typ
-							}
-						}
-
-					case "object":
-						t := objVal.Type
-						if objVal.Items != nil {
-							t = objVal.Items.Value.Type
-						}
-						typ, ok := typeConvMap[t]
-						if ok {
-							g.pp("	%s %s `json:\"%s%s\"`", Depunct(name, true), typ, name, omitempty)
-							propertyTypes[name] = typ
-						}
-
-					default:
-						typ, ok := typeConvMap[objVal.Type]
-						if ok {
-							g.pp("	%s %s `json:\"%s%s\"`", Depunct(name, true), typ, name, omitempty)
-							propertyTypes[name] = typ
-						}
-					}
-				} else {
-					typ, ok := typeConvMap[val.Type]
-					if ok {
-						g.pp("	%s %s `json:\"%s%s\"`", Depunct(name, true), typ, name, omitempty)
-						propertyTypes[name] = typ
-					}
-				}
+	for _, field := range model.Fields {
+		field.Getter = g.modelField(m.Name, "Get "+field.JSON, "Get"+field.Name)
+	}
 
-			case "array":
-				if val.Items.Value != nil {
-					typ, ok := typeConvMap[val.Items.Value.Type]
-					if ok {
-						g.pp("	%s []%s `json:\"%s%s\"`", Depunct(name, true), typ, name, omitempty)
-						propertyTypes[name] = "[]" + typ
-					}
-				}
+	return model
+}
 
-			default:
-				typ, ok := typeConvMap[val.Type]
-				if ok {
-					g.pp("	%s %s `json:\"%s%s\"`", Depunct(name, true), typ, name, omitempty)
-					propertyTypes[name] = typ
-				}
-			}
+// buildTypeModel builds the model of the schema which is not the object schema, such as the array, map and enum
+// schemas, which is the defined type of the Go type of the schema.
+//
+// The model of $ref, and of the schema which has any type, is the type alias which has no Validate method.
+func (g *Generator) buildTypeModel(model *ModelData, m *ir.Model) {
+	t := m.Type
+	switch t.Kind {
+	case ir.Ref:
+		model.Alias, model.Type = true, "interface{}"
+		if t.Elem == nil {
+			g.warnf(modelPointer(m.Name), "model %s is any type, $ref %s is not found", m.Name, t.Ref)
+			return
+		}
+		model.Type = g.modelType(t.Name)
+		return
 
-		case property.Value != nil:
-			switch val := property.Value; val.Type {
-			case "object":
-				if val.AdditionalProperties != nil && val.AdditionalProperties.Value != nil {
-					switch objVal := val.AdditionalProperties.Value; objVal.Type {
-					case "array":
-						if objVal.Items.Value != nil {
-							typ, ok := typeConvMap[objVal.Items.Value.Type]
-							if ok {
-								g.pp("	%s []%s `json:\"%s%s\"`", Depunct(name, true), typ, name, omitempty)
-								propertyTypes[name] = "[]" + typ
-							}
-						}
-
-					case "object":
-						t := objVal.Type
-						if objVal.Items != nil {
-							t = objVal.Items.Value.Type
-						}
-						typ, ok := typeConvMap[t]
-						if ok {
-							g.pp("	%s %s `json:\"%s%s\"`", Depunct(name, true), typ, name, omitempty)
-							propertyTypes[name] = typ
-						}
-
-					default:
-						typ, ok := typeConvMap[objVal.Type]
-						if ok {
-							g.pp("	%s %s `json:\"%s%s\"`", Depunct(name, true), typ, name, omitempty)
-							propertyTypes[name] = typ
-						}
-					}
-				} else {
-					typ, ok := typeConvMap[val.Type]
-					if ok {
-						g.pp("	%s %s `json:\"%s%s\"`", Depunct(name, true), typ, name, omitempty)
-						propertyTypes[name] = typ
-					}
-				}
+	case ir.Any, ir.Union:
+		model.Alias, model.Type = true, "interface{}"
+		return
+	}
 
-			case "array":
-				if val.Items.Value != nil {
-					typ, ok := typeConvMap[val.Items.Value.Type]
-					if ok {
-						g.pp("	%s []%s `json:\"%s%s\"`", Depunct(name, true), typ, name, omitempty)
-						propertyTypes[name] = "[]" + typ
-					}
-				}
+	typ, ok := g.schemaFieldType(t)
+	if !ok {
+		g.warnf(modelPointer(m.Name), "model %s is any type, the schema has no Go type", m.Name)
+		model.Alias, model.Type = true, "interface{}"
+		return
+	}
+	model.Type = typ
+	if g.isMappedType(typ) { // keeps the methods of the mapped type such as MarshalJSON
+		model.Alias = true
+		return
+	}
 
-			default:
-				typ, ok := typeConvMap[val.Type]
-				if ok {
-					g.pp("	%s %s `json:\"%s%s\"`", Depunct(name, true), typ, name, omitempty)
-					propertyTypes[name] = typ
-				}
-			}
+	if t.Kind == ir.Enum {
+		model.Consts = g.enumConsts(m.Name, model.Name, typ, t.Values)
+	}
+	if hasConstraints(typ, t) {
+		expr := "*" + model.Receiver
+		if typ == "string" {
+			expr = "string(" + expr + ")"
 		}
+		model.Checks = g.checks(expr, typ, `""`, modelPointer(m.Name), t)
 	}
-	g.pp("}\n")
+}
 
-	for _, field := range propertyNames {
-		reciever := strings.ToLower(string(modelName[0]))
-		fieldType := propertyTypes[field]
-		if fieldType == "" {
+// enumConsts returns the constants of the enumerated values of the typeName enum model, which are named by the type
+// name followed by the value such as "StatusAvailable". The values which have no Go literal of typ are skipped.
+func (g *Generator) enumConsts(modelName, typeName, typ string, values []interface{}) []*ConstData {
+	var consts []*ConstData
+	for _, value := range values {
+		var lit, suffix string
+		switch v := value.(type) {
+		case string:
+			if typ != "string" {
+				continue
+			}
+			lit, suffix = strconv.Quote(v), g.depunct(v, true)
+		case float64:
+			if !isNumericType(typ) || (strings.HasPrefix(typ, "int") && v != math.Trunc(v)) {
+				continue
+			}
+			lit = formatFloat(v)
+			suffix = enumNumberReplacer.Replace(lit)
+		default:
 			continue
 		}
-		field := Depunct(field, true)
-
-		g.pp("// Get%[1]s returns the %[1]s field value if set, zero value otherwise.", field)
-		g.pp("func (%s *%s) Get%s() (ret %s) {", reciever, Depunct(modelName, true), field, fieldType)
-		g.pp(" 	if %[1]s == nil {", reciever)
-		g.pp(" 		return ret")
-		g.pp(" 	}")
-
-		switch fieldType {
-		case "map[string]interface{}", "interface{}":
-			g.pp(" 	return %s.%s", reciever, field)
-
-		default:
-			g.p(" 	return ")
-			g.pp("%s.%s", reciever, field)
+		if suffix == "" {
+			continue
 		}
-		g.pp("}\n")
-
-		// g.p("\n")
-
-		// g.pp("// Has%s reports whether the field has been set", field)
-		// g.pp("func (%s *%s) Has%s() bool {", reciever, Depunct(modelName, true), field)
-		// g.pp(" 	if %[1]s != nil && %[1]s.%[2]s != nil {", reciever, field)
-		// g.pp(" 		return true")
-		// g.pp(" 	}")
-		// g.pp(" 	return false")
-		// g.pp("}")
-		//
-		// g.p("\n")
-
-		// g.pp("// Set%[1]s gets a reference to the given string and assigns it to the %[1]s field.", field)
-		//
-		// // TODO(zchee): parse actual field type
-		// switch {
-		// case fieldType == "map[string]interface{}", fieldType == "interface{}":
-		// 	g.pp("func (%s *%s) Set%s(val %s) {", reciever, Depunct(modelName, true), field, fieldType)
-		// 	g.pp("	%s.%s = val", reciever, field)
-		//
-		// default:
-		// 	if typ, ok := typeConvMap[fieldType]; ok {
-		// 		var hasPtr bool
-		// 		if !strings.HasPrefix(typ, "[]") {
-		// 			hasPtr = true
-		// 		}
-		// 		g.p("func (%s ", reciever)
-		// 		if hasPtr {
-		// 			g.p("*")
-		// 		}
-		// 		g.pp("%s) Set%s(val %s) {", Depunct(modelName, true), field, typ)
-		//
-		// 		g.p("	%s.%s = ", reciever, field)
-		// 		if hasPtr {
-		// 			g.p("&")
-		// 		}
-		// 		g.pp("val")
-		// 	}
-		// }
-		// g.pp("}")
+		name := g.declName("enum "+modelKey(modelName)+" "+lit, typeName+suffix)
+		g.declareSource(name, modelPointer(modelName))
+		consts = append(consts, &ConstData{Name: name, Value: lit})
 	}
+
+	return consts
 }
 
-// WriteSchemaDescriptor writes base64 encoded, gzipped compressed and JSON marshaled schema spec into generated file.
-func (g *Generator) WriteSchemaDescriptor() {
-	if g.openAPI == nil {
-		return // no-op
-	}
+// enumNumberReplacer replaces the sign and the decimal point of the numeric enum value to the identifier, such as
+// "-0.5" to "Minus0_5".
+var enumNumberReplacer = strings.NewReplacer("-", "Minus", "+", "", ".", "_")
 
-	in := g.openAPI
-	data, err := json.Marshal(in)
-	if err != nil {
-		panic(err)
+// propertyType returns the Go type of the model field of property.
+//
+// The $ref to the model is the pointer to the model, and the $ref to the other schema is the type of the schema.
+func (g *Generator) propertyType(property *ir.Type) (string, bool) {
+	switch {
+	case property == nil:
+		return "", false
+
+	case property.Kind == ir.Ref:
+		if property.Elem == nil {
+			return "", false
+		}
+		if isModelSchema(property.Elem) {
+			return "*" + g.modelType(property.Name), true
+		}
+		return g.propertyType(property.Elem)
 	}
 
-	buf := &bytes.Buffer{}
-	zw, err := gzip.NewWriterLevel(buf, gzip.BestCompression)
-	if err != nil {
-		panic(err)
-	}
-	if _, err := zw.Write(data); err != nil {
-		panic(err)
-	}
-	if err := zw.Close(); err != nil {
-		panic(err)
+	return g.schemaFieldType(property)
+}
+
+// unresolvedRef returns the $ref of t or its elements which is not found in the components, or empty if none.
+func unresolvedRef(t *ir.Type) string {
+	for ; t != nil; t = t.Elem {
+		switch t.Kind {
+		case ir.Ref:
+			if t.Elem == nil {
+				return t.Ref
+			}
+			return ""
+		case ir.Map, ir.Slice:
+		default:
+			return ""
+		}
 	}
-	b := buf.Bytes()
 
-	g.pp("// SchemaDescriptor returns the Schema file descriptor which is generated code to this file.")
-	g.pp("func SchemaDescriptor() (interface{}, error) {")
-	g.pp("	zr, err := gzip.NewReader(bytes.NewReader(fileDescriptor))")
-	g.pp("	if err != nil { return nil, err }")
-	g.p("\n")
-	g.pp("	var buf bytes.Buffer")
-	g.pp("	_, err = buf.ReadFrom(zr)")
-	g.pp("	if err != nil { return nil, err }")
-	g.p("\n")
-	g.pp("	var v interface{}")
-	g.pp("	if err := json.NewDecoder(bytes.NewReader(buf.Bytes())).Decode(&v); err != nil {")
-	g.pp("		return nil, err")
-	g.pp("	}")
-	g.p("\n")
-	g.pp("	return v, nil")
-	g.pp("}")
-
-	g.p("\n")
-
-	g.pp("// fileDescriptor gzipped JSON marshaled Schema object.")
-	g.pp("var fileDescriptor = []byte{")
-	g.pp("	// %d bytes of a gzipped Schema file descriptor", len(b))
+	return ""
+}
 
-	for len(b) > 0 {
-		n := 16
-		if n > len(b) {
-			n = len(b)
+// schemaFieldType returns the Go type of the model field which has the inline schema.
+func (g *Generator) schemaFieldType(t *ir.Type) (string, bool) {
+	switch t.Kind {
+	case ir.Map:
+		if typ, ok := g.propertyType(t.Elem); ok {
+			return "map[string]" + typ, true
 		}
+		return g.fieldType(t)
 
-		s := ""
-		for _, c := range b[:n] {
-			s += fmt.Sprintf("0x%02x, ", c)
+	case ir.Slice:
+		if typ, ok := g.propertyType(t.Elem); ok {
+			return "[]" + typ, true
 		}
-		g.pp("	%s", s)
+		return "", false
 
-		b = b[n:]
+	case ir.Any, ir.Union:
+		return "interface{}", true
+
+	default:
+		return g.fieldType(t)
 	}
-	g.pp("}")
 }
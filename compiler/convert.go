@@ -4,6 +4,7 @@
 package compiler
 
 import (
+	"reflect"
 	"sort"
 	"strings"
 	"unicode"
@@ -40,16 +41,15 @@ func IsDigit(c byte) bool {
 
 // SortedMapKeys returns the keys of m, which must be a map[string]T, in sorted order.
 func SortedMapKeys(v interface{}) []string {
-	m, ok := v.(map[string]interface{})
-	if !ok {
+	m := reflect.ValueOf(v)
+	if m.Kind() != reflect.Map || m.Type().Key().Kind() != reflect.String {
 		return nil
 	}
 
-	keys := make([]string, len(m))
-	i := 0
-	for k := range m {
-		keys[i] = k
-		i++
+	keys := make([]string, 0, m.Len())
+	iter := m.MapRange()
+	for iter.Next() {
+		keys = append(keys, iter.Key().String())
 	}
 	sort.Strings(keys)
 
@@ -59,6 +59,11 @@ func SortedMapKeys(v interface{}) []string {
 // Depunct removes '-', '.', '$', '/', '_' from identifers, making the
 // following character uppercase. Multiple '_' are preserved.
 func Depunct(ident string, needCap bool) string {
+	return depunct(ident, needCap, nil)
+}
+
+// depunct is Depunct which also keeps the initialisms in the consistent case.
+func depunct(ident string, needCap bool, initialisms map[string]bool) string {
 	var sb strings.Builder
 
 	preserve := false
@@ -84,7 +89,7 @@ func Depunct(ident string, needCap bool) string {
 		sb.WriteByte(byte(c))
 	}
 
-	s := FixName(sb.String())
+	s := fixName(sb.String(), initialisms)
 	if s == "Typ" {
 		return "Type" // special case
 	}
@@ -0,0 +1,139 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	"github.com/zchee/go-openapi-tools/diag"
+)
+
+// discardLogger is the default logger of the Generator, which discards all records.
+var discardLogger = slog.New(slog.NewTextHandler(io.Discard, &slog.HandlerOptions{Level: slog.LevelError + 1}))
+
+// WithLogger sets the logger of the progress of the generation, such as the rendered and written files.
+//
+// The Generator logs nothing by default. The warnings of the schema are not logged, those are returned by Diagnostics.
+func WithLogger(logger *slog.Logger) Option {
+	return func(g *Generator) {
+		if logger != nil {
+			g.logger = logger
+		}
+	}
+}
+
+// warnf records the warning at the spec location of pointer, which is returned by Diagnostics.
+func (g *Generator) warnf(pointer, format string, args ...interface{}) {
+	g.diags = append(g.diags, diag.Warnf(pointer, format, args...))
+}
+
+// infof records the informational diagnostic at the spec location of pointer, which is returned by Diagnostics.
+func (g *Generator) infof(pointer, format string, args ...interface{}) {
+	g.diags = append(g.diags, diag.Infof(pointer, format, args...))
+}
+
+// Diagnostics returns the warnings of the OpenAPI 3.1 schema conversion, and the warnings and the renamed identifiers
+// of the generation, located in the schema. The renamed package level identifiers are the warnings, the others and the
+// imports which are not type-checked are informational.
+//
+// The errors which fail the generation are returned by Generate as diag.List or *diag.Diagnostic if located in the
+// schema.
+func (g *Generator) Diagnostics() diag.List {
+	diags := append(append(diag.List(nil), g.loadDiags...), g.diags...)
+	for _, r := range g.Renames() {
+		severity := diag.Info
+		if r.Scope == packageScope {
+			severity = diag.Warning // changes the public API, such as the service type of the tag
+		}
+		diags = append(diags, &diag.Diagnostic{
+			Severity: severity,
+			Msg:      r.String(),
+			Pointer:  g.scopePointer(r.Scope, r.Name),
+		})
+	}
+	g.locate(diags)
+
+	return diags
+}
+
+// locate sets the file and the source positions of the diagnostics in the schema, which is read by New or
+// NewFromReader.
+func (g *Generator) locate(diags diag.List) {
+	var pointer func(string) string
+	if g.schemaType == swaggerSchema {
+		pointer = diag.SwaggerPointer
+	}
+	diags.Locate(g.filename, g.source, pointer)
+}
+
+// locateError locates the diagnostics of err in the schema, and returns err.
+func (g *Generator) locateError(err error) error {
+	var diags diag.List
+	if errors.As(err, &diags) {
+		g.locate(diags)
+	}
+	var d *diag.Diagnostic
+	if errors.As(err, &d) {
+		g.locate(diag.List{d})
+	}
+
+	return err
+}
+
+// scopePointer returns the spec location of the identifier of name in the namespace of scope, or empty if unknown.
+func (g *Generator) scopePointer(scope, name string) string {
+	switch {
+	case strings.HasPrefix(name, "#/"):
+		return name // the package level identifiers of the models
+
+	case strings.HasPrefix(name, "webhook "):
+		return name[strings.IndexByte(name, '#'):] // the receivers of the webhooks, keyed by the kind and the pointer
+
+	case strings.HasPrefix(name, "tag "):
+		for i, tag := range g.openAPI.Tags {
+			if tag != nil && serviceName(tag.Name) == strings.TrimPrefix(name, "tag ") {
+				return diag.Pointer("tags", strconv.Itoa(i))
+			}
+		}
+		return ""
+	}
+
+	// the scopes are the kind and the name of the declaration, such as "model Pet" or "call ListPetsCall"
+	kind, decl := scope, ""
+	if idx := strings.IndexByte(scope, ' '); idx >= 0 {
+		kind, decl = scope[:idx], scope[idx+1:]
+	}
+	switch kind {
+	case "model":
+		pointer := g.declSource(decl)
+		if pointer == "" || strings.HasPrefix(name, "Get ") {
+			return pointer
+		}
+		return pointer + strings.TrimPrefix(diag.Pointer("properties", name), "#")
+
+	case "params", "handler":
+		return g.namedOperationPointer(decl)
+
+	case "serverAdapter", "fake":
+		return g.namedOperationPointer(name)
+
+	default:
+		return g.declSource(decl)
+	}
+}
+
+// namedOperationPointer returns the JSON pointer of the operation of the Go method name, or empty if not found.
+func (g *Generator) namedOperationPointer(name string) string {
+	for op, opName := range g.opNames {
+		if opName == name {
+			return operationPointer(op)
+		}
+	}
+
+	return ""
+}
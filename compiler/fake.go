@@ -0,0 +1,24 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+// buildFake returns the template data of the fake subpackage which provides the in-memory fake of the API.
+func (g *Generator) buildFake(services []*ServiceData) *FakeData {
+	fake := &FakeData{
+		Package:  g.pkgName,
+		Import:   &ImportData{Path: g.fake},
+		Services: services,
+	}
+	if packageName(g.fake) != g.pkgName {
+		fake.Import.Alias = g.pkgName
+	}
+
+	for _, svc := range services {
+		for _, o := range svc.Operations {
+			o.FakeField = g.fakeField(svc.Name, o.Name)
+		}
+	}
+
+	return fake
+}
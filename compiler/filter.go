@@ -0,0 +1,94 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	pathpkg "path"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// Filter selects the operations by the tags, operation IDs or paths.
+//
+// The operation matches Filter if it matches any of the rules.
+type Filter struct {
+	Tags       []string // tag names
+	Operations []string // operation IDs
+	Paths      []string // path patterns, such as "/pets/*". see path.Match for the syntax
+}
+
+// isEmpty reports whether f has no rules.
+func (f *Filter) isEmpty() bool {
+	return f == nil || len(f.Tags)+len(f.Operations)+len(f.Paths) == 0
+}
+
+// match reports whether the op of path matches f.
+func (f *Filter) match(path string, op *openapi3.Operation) bool {
+	if f == nil {
+		return false
+	}
+	for _, tag := range op.Tags {
+		if contains(tag, f.Tags) {
+			return true
+		}
+	}
+	if op.OperationID != "" && contains(op.OperationID, f.Operations) {
+		return true
+	}
+	for _, pattern := range f.Paths {
+		if ok, _ := pathpkg.Match(pattern, path); ok {
+			return true
+		}
+		// matches the sub paths by the trailing "/**"
+		if prefix := strings.TrimSuffix(pattern, "/**"); prefix != pattern && (path == prefix || strings.HasPrefix(path, prefix+"/")) {
+			return true
+		}
+	}
+
+	return false
+}
+
+// WithFilter generates only the operations which match include and do not match exclude.
+//
+// The empty include matches all operations.
+func WithFilter(include, exclude Filter) Option {
+	return func(g *Generator) {
+		g.include = &include
+		g.exclude = &exclude
+	}
+}
+
+// applyFilter removes the operations which are not selected by the filters from the schema.
+//
+// The tags which no longer have operations are also removed.
+func (g *Generator) applyFilter() {
+	if g.include.isEmpty() && g.exclude.isEmpty() {
+		return
+	}
+
+	usedTags := make(map[string]bool)
+	for path, item := range g.openAPI.Paths {
+		for method, op := range item.Operations() {
+			if (!g.include.isEmpty() && !g.include.match(path, op)) || g.exclude.match(path, op) {
+				item.SetOperation(method, nil)
+				continue
+			}
+			for _, tag := range op.Tags {
+				usedTags[tag] = true
+			}
+		}
+		if len(item.Operations()) == 0 {
+			delete(g.openAPI.Paths, path)
+		}
+	}
+
+	tags := g.openAPI.Tags[:0]
+	for _, tag := range g.openAPI.Tags {
+		if usedTags[tag.Name] {
+			tags = append(tags, tag)
+		}
+	}
+	g.openAPI.Tags = tags
+}
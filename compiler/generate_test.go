@@ -0,0 +1,127 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"bytes"
+	"flag"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("update", false, "update the golden files under testdata")
+
+// goldenSuffix is the suffix of the golden files, which go build does not compile.
+const goldenSuffix = ".golden"
+
+func TestGenerateGolden(t *testing.T) {
+	const runs = 5
+
+	tests := map[string]struct {
+		schema string
+		opts   []Option
+	}{
+		"petstore": {
+			schema: filepath.Join("testdata", "petstore.yaml"),
+			opts:   []Option{WithServer(), WithInterfaces(), WithFake("example.com/petstore")},
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			var want map[string][]byte
+			for i := 0; i < runs; i++ {
+				g, err := New(SchemaNameOpenAPI, name, tt.schema, tt.opts...)
+				if err != nil {
+					t.Fatal(err)
+				}
+				files, err := g.GenerateFiles()
+				if err != nil {
+					t.Fatal(err)
+				}
+				if want == nil {
+					want = files
+					continue
+				}
+				compareFiles(t, want, files)
+			}
+
+			dir := filepath.Join("testdata", name)
+			if *update {
+				writeGolden(t, dir, want)
+				return
+			}
+			compareFiles(t, readGolden(t, dir), want)
+		})
+	}
+}
+
+// compareFiles reports the files of got which are not byte-identical to want.
+func compareFiles(t *testing.T, want, got map[string][]byte) {
+	t.Helper()
+
+	for _, name := range SortedMapKeys(want) {
+		data, ok := got[name]
+		if !ok {
+			t.Errorf("%s is not generated", name)
+			continue
+		}
+		if !bytes.Equal(data, want[name]) {
+			t.Errorf("%s differs:\n%s", name, data)
+		}
+	}
+	for _, name := range SortedMapKeys(got) {
+		if _, ok := want[name]; !ok {
+			t.Errorf("%s is generated unexpectedly", name)
+		}
+	}
+}
+
+// readGolden reads the golden files under dir, keyed by the slash separated file name without goldenSuffix.
+func readGolden(t *testing.T, dir string) map[string][]byte {
+	t.Helper()
+
+	files := make(map[string][]byte)
+	err := filepath.WalkDir(dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(dir, path)
+		if err != nil {
+			return err
+		}
+		files[strings.TrimSuffix(filepath.ToSlash(rel), goldenSuffix)] = data
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("failed to read golden files, run the test with -update: %v", err)
+	}
+
+	return files
+}
+
+// writeGolden replaces the golden files under dir with files.
+func writeGolden(t *testing.T, dir string, files map[string][]byte) {
+	t.Helper()
+
+	if err := os.RemoveAll(dir); err != nil {
+		t.Fatal(err)
+	}
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name)+goldenSuffix)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
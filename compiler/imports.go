@@ -0,0 +1,219 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"bytes"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	pathpkg "path"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// stdPackages is the standard packages which the generated code may reference, keyed by the package name.
+var stdPackages = map[string]string{
+	"bufio":   "bufio",
+	"bytes":   "bytes",
+	"context": "context",
+	"errors":  "errors",
+	"fmt":     "fmt",
+	"gzip":    "compress/gzip",
+	"hash":    "hash",
+	"hex":     "encoding/hex",
+	"hmac":    "crypto/hmac",
+	"http":    "net/http",
+	"io":      "io",
+	"ioutil":  "io/ioutil",
+	"json":    "encoding/json",
+	"log":     "log",
+	"mail":    "net/mail",
+	"math":    "math",
+	"net":     "net",
+	"os":      "os",
+	"path":    "path",
+	"reflect": "reflect",
+	"regexp":  "regexp",
+	"sort":    "sort",
+	"strconv": "strconv",
+	"strings": "strings",
+	"sync":    "sync",
+	"time":    "time",
+	"unicode": "unicode",
+	"url":     "net/url",
+	"utf8":    "unicode/utf8",
+}
+
+// packageName returns the package name of the import path by the convention, such as "uuid" of
+// "github.com/google/uuid", "yaml" of "gopkg.in/yaml/v3" and "gobar" of "example.com/go-bar".
+func packageName(importPath string) string {
+	if name, ok := stdPackageName(importPath); ok {
+		return name
+	}
+
+	name := pathpkg.Base(importPath)
+	if majorVersionRe.MatchString(name) && pathpkg.Dir(importPath) != "." {
+		name = pathpkg.Base(pathpkg.Dir(importPath))
+	}
+
+	return strings.NewReplacer("-", "", ".", "").Replace(name)
+}
+
+// stdPackageName returns the package name of the standard package in stdPackages.
+func stdPackageName(importPath string) (string, bool) {
+	for name, path := range stdPackages {
+		if path == importPath {
+			return name, true
+		}
+	}
+
+	return "", false
+}
+
+// knownPackages returns the packages which are imported if referenced by the generated code, keyed by the package
+// name. Those are the standard packages, the validation middleware and the packages of the mapped types.
+func (g *Generator) knownPackages() map[string]externalPackage {
+	pkgs := make(map[string]externalPackage, len(stdPackages)+len(g.mappedTypes)+1)
+	for name, path := range stdPackages {
+		pkgs[name] = externalPackage{pkg: path}
+	}
+	pkgs[packageName(middlewarePkg)] = externalPackage{pkg: middlewarePkg}
+	for _, key := range SortedMapKeys(g.mappedTypes) {
+		if pkg := g.mappedTypes[key].pkg; pkg.pkg != "" {
+			pkgs[pkg.name()] = pkg
+		}
+	}
+
+	return pkgs
+}
+
+// name returns the package name of p in the file which imports p.
+func (p externalPackage) name() string {
+	if p.alias != "" {
+		return p.alias
+	}
+
+	return packageName(p.pkg)
+}
+
+// fixImports rewrites the imports of the generated Go source of the file name to the packages which it references.
+//
+// The imports which the source declares are kept if referenced, and the other referenced packages are imported from
+// knownPackages. The unknown references are left as is, those are reported by the compiler.
+func (g *Generator) fixImports(name string, src []byte) ([]byte, error) {
+	fset := token.NewFileSet()
+	f, err := parser.ParseFile(fset, name, src, parser.ParseComments)
+	if err != nil {
+		return nil, fmt.Errorf("could not parse %s: %w", name, err)
+	}
+
+	declared := make(map[string]externalPackage)
+	var keep []externalPackage // blank and dot imports
+	for _, spec := range f.Imports {
+		path, err := strconv.Unquote(spec.Path.Value)
+		if err != nil {
+			return nil, fmt.Errorf("invalid import path %s of %s: %w", spec.Path.Value, name, err)
+		}
+		pkg := externalPackage{pkg: path}
+		if spec.Name != nil {
+			pkg.alias = spec.Name.Name
+		}
+		switch pkg.alias {
+		case "_", ".":
+			keep = append(keep, pkg)
+		default:
+			declared[pkg.name()] = pkg
+		}
+	}
+
+	known := g.knownPackages()
+	imports := keep
+	seen := make(map[string]bool)
+	ast.Inspect(f, func(n ast.Node) bool {
+		sel, ok := n.(*ast.SelectorExpr)
+		if !ok {
+			return true
+		}
+		// the identifiers which are not resolved in the file are the package names, or declared by the other files
+		id, ok := sel.X.(*ast.Ident)
+		if !ok || id.Obj != nil || seen[id.Name] {
+			return true
+		}
+		seen[id.Name] = true
+
+		if pkg, ok := declared[id.Name]; ok {
+			imports = append(imports, pkg)
+		} else if pkg, ok := known[id.Name]; ok {
+			imports = append(imports, pkg)
+		}
+		return true
+	})
+
+	// replaces the import declarations, which are always at the top of the file, to the new one
+	var decl bytes.Buffer
+	start := fset.Position(f.Name.End()).Offset
+	end := start
+	if len(imports) > 0 {
+		decl.WriteString("\n\n")
+	}
+	for _, d := range f.Decls {
+		gen, ok := d.(*ast.GenDecl)
+		if !ok || gen.Tok != token.IMPORT {
+			break
+		}
+		if end == fset.Position(f.Name.End()).Offset {
+			start = fset.Position(gen.Pos()).Offset
+			decl.Reset()
+		}
+		end = fset.Position(gen.End()).Offset
+	}
+	writeImportDecl(&decl, imports)
+
+	out := make([]byte, 0, len(src)+decl.Len())
+	out = append(out, src[:start]...)
+	out = append(out, decl.Bytes()...)
+	out = append(out, src[end:]...)
+
+	return formatSource(name, out)
+}
+
+// isStdImportPath reports whether the import path is the standard package, which has no dot in the first element.
+func isStdImportPath(path string) bool {
+	return !strings.Contains(strings.SplitN(path, "/", 2)[0], ".")
+}
+
+// writeImportDecl writes the import declaration of pkgs, the standard packages first and then the others.
+func writeImportDecl(w *bytes.Buffer, pkgs []externalPackage) {
+	if len(pkgs) == 0 {
+		return
+	}
+
+	var std, others []externalPackage
+	for _, pkg := range pkgs {
+		if isStdImportPath(pkg.pkg) {
+			std = append(std, pkg)
+		} else {
+			others = append(others, pkg)
+		}
+	}
+
+	w.WriteString("import (\n")
+	for i, group := range [][]externalPackage{std, others} {
+		if i > 0 && len(std) > 0 && len(group) > 0 {
+			w.WriteString("\n")
+		}
+		sort.Slice(group, func(i, j int) bool { return group[i].pkg < group[j].pkg })
+		for _, pkg := range group {
+			w.WriteString("\t")
+			if pkg.alias != "" {
+				w.WriteString(pkg.alias + " ")
+			}
+			w.WriteString(strconv.Quote(pkg.pkg) + "\n")
+		}
+	}
+	w.WriteString(")")
+}
@@ -54,10 +54,28 @@ var commonInitialisms = map[string]bool{
 	"XSS":   true,
 }
 
+// WithInitialisms adds the initialisms such as "OAI" to the common initialisms, which the Go identifiers of the
+// Generator keep in the consistent case, such as OAIClient instead of OaiClient.
+func WithInitialisms(initialisms ...string) Option {
+	return func(g *Generator) {
+		if g.initialisms == nil {
+			g.initialisms = make(map[string]bool, len(initialisms))
+		}
+		for _, s := range initialisms {
+			g.initialisms[strings.ToUpper(s)] = true
+		}
+	}
+}
+
 // FixName returns a fixed name based by Go naming idiom.
 //
 // ported from golang.org/x/lint/lint.go
 func FixName(name string) (should string) {
+	return fixName(name, nil)
+}
+
+// fixName is FixName which also keeps the initialisms in the consistent case.
+func fixName(name string, initialisms map[string]bool) string {
 	// Fast path for simple cases: "_" and all lowercase.
 	if name == "_" {
 		return name
@@ -116,7 +134,7 @@ func FixName(name string) (should string) {
 
 		// [w,i) is a word.
 		word := string(runes[w:i])
-		if u := strings.ToUpper(word); commonInitialisms[u] {
+		if u := strings.ToUpper(word); commonInitialisms[u] || initialisms[u] {
 			// Keep consistent case, which is lowercase only at the start.
 			if w == 0 && unicode.IsLower(runes[w]) {
 				u = strings.ToLower(u)
@@ -0,0 +1,43 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import "testing"
+
+func TestWithInitialisms(t *testing.T) {
+	g := new(Generator)
+	WithInitialisms("oai", "K8S")(g)
+
+	tests := map[string]struct {
+		ident   string
+		want    string
+		depunct func(string, bool) string
+	}{
+		"Generator": {
+			ident:   "oai_k8s-client_id",
+			want:    "OAIK8SClientID",
+			depunct: g.depunct,
+		},
+		"Depunct": {
+			ident:   "oai_k8s-client_id",
+			want:    "OaiK8sClientID",
+			depunct: Depunct,
+		},
+		"OtherGenerator": {
+			ident:   "oai_k8s-client_id",
+			want:    "OaiK8sClientID",
+			depunct: new(Generator).depunct,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			if got := tt.depunct(tt.ident, true); got != tt.want {
+				t.Fatalf("depunct(%q) = %q, want %q", tt.ident, got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,105 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// manifestFileName is the file name of the manifest, which lists the generated files.
+const manifestFileName = ".oapi-generator-manifest"
+
+// WithClean removes the stale generated files under the output directory after generation.
+//
+// The stale files are the files which are no longer generated, and listed in the previous manifest or have the
+// generated code comment of oapi-generator. Any other files, such as generated by stringer, are never removed.
+func WithClean() Option {
+	return func(g *Generator) {
+		g.clean = true
+	}
+}
+
+// writeManifest adds the manifest of the generated files to the files.
+func (g *Generator) writeManifest() {
+	var buf bytes.Buffer
+	buf.WriteString("# " + strings.TrimPrefix(headerFmt, "// ") + "\n")
+	buf.WriteString("# The files generated by oapi-generator, which are removed by -clean if no longer generated.\n")
+	for _, name := range SortedMapKeys(g.files) {
+		buf.WriteString(filepath.ToSlash(name) + "\n")
+	}
+
+	g.files[manifestFileName] = buf.Bytes()
+}
+
+// readManifest reads the manifest under dst, and returns the listed file names.
+//
+// The file names which are not local to dst are ignored. It returns nil if the manifest does not exist.
+func readManifest(dst string) ([]string, error) {
+	data, err := os.ReadFile(filepath.Join(dst, manifestFileName))
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	var names []string
+	sc := bufio.NewScanner(bytes.NewReader(data))
+	for sc.Scan() {
+		line := strings.TrimSpace(sc.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		name := filepath.FromSlash(line)
+		if !isLocalPath(name) {
+			continue // never touch the files outside of dst
+		}
+		names = append(names, filepath.Clean(name))
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read manifest: %w", err)
+	}
+
+	return names, nil
+}
+
+// isLocalPath reports whether the path is relative and does not escape from the directory by "..".
+func isLocalPath(path string) bool {
+	if path == "" || filepath.IsAbs(path) || filepath.VolumeName(path) != "" {
+		return false
+	}
+	clean := filepath.Clean(path)
+
+	return clean != "." && clean != ".." && !strings.HasPrefix(clean, ".."+string(filepath.Separator))
+}
+
+// removeStaleFiles removes the stale generated files under dst, and the directories which became empty.
+func (g *Generator) removeStaleFiles(dst string, stale []string) error {
+	dirs := make(map[string]bool)
+	for _, name := range stale {
+		if err := os.Remove(filepath.Join(dst, name)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return fmt.Errorf("failed to remove stale file %s: %w", name, err)
+		}
+		g.logger.Info("removed stale file", "file", name)
+		if dir := filepath.Dir(name); dir != "." {
+			dirs[dir] = true
+		}
+	}
+
+	for dir := range dirs {
+		entries, err := os.ReadDir(filepath.Join(dst, dir))
+		if err == nil && len(entries) == 0 {
+			_ = os.Remove(filepath.Join(dst, dir))
+		}
+	}
+
+	return nil
+}
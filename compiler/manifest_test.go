@@ -0,0 +1,76 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestStaleFiles(t *testing.T) {
+	const (
+		generated = headerFmt + "\n\npackage petstore\n"
+		stringer  = "// Code generated by \"stringer -type=Kind\"; DO NOT EDIT.\n\npackage petstore\n"
+		mockgen   = "// Code generated by MockGen. DO NOT EDIT.\n\npackage fake\n"
+		user      = "package petstore\n"
+	)
+
+	dst := t.TempDir()
+	files := map[string]string{
+		manifestFileName:                 "# header\nclient.go\napi_store.go\nmodel_old.txt\n../outside.go\n",
+		"client.go":                      generated,
+		"api_store.go":                   user, // listed in the manifest, the header is edited
+		"model_old.txt":                  "old",
+		"model_pet.go":                   generated,
+		"kind_string.go":                 stringer,
+		"user.go":                        user,
+		filepath.Join("fake", "fake.go"): generated,
+		filepath.Join("fake", "mock.go"): mockgen,
+		filepath.Join("other", "old.go"): generated, // the Generator does not write to other
+	}
+	for name, data := range files {
+		path := filepath.Join(dst, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(data), 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	g := &Generator{
+		files: map[string][]byte{
+			"client.go": []byte(generated),
+		},
+	}
+	got, err := g.staleFiles(dst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []string{"api_store.go", filepath.Join("fake", "fake.go"), "model_old.txt", "model_pet.go"}
+	if !reflect.DeepEqual(got, want) {
+		t.Fatalf("staleFiles() = %q, want %q", got, want)
+	}
+}
+
+func TestIsLocalPath(t *testing.T) {
+	tests := map[string]bool{
+		"client.go":                        true,
+		filepath.Join("fake", "fake.go"):   true,
+		filepath.Join("fake", "..", "x"):   true,
+		"":                                 false,
+		".":                                false,
+		"..":                               false,
+		filepath.Join("..", "outside.go"):  false,
+		filepath.Join("fake", "..", ".."):  false,
+		string(filepath.Separator) + "etc": false,
+	}
+	for path, want := range tests {
+		if got := isLocalPath(path); got != want {
+			t.Errorf("isLocalPath(%q) = %t, want %t", path, got, want)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"bytes"
+	"io"
+	"io/fs"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// mapFS is the read-only filesystem of the generated files, keyed by the slash separated file name such as
+// "fake/fake.go". The directories are implied by the file names.
+type mapFS map[string][]byte
+
+var _ fs.ReadFileFS = mapFS(nil)
+
+// Open implements fs.FS.
+func (m mapFS) Open(name string) (fs.File, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrInvalid}
+	}
+	if data, ok := m[name]; ok {
+		return &mapFile{
+			info:   mapFileInfo{name: path.Base(name), size: int64(len(data))},
+			Reader: bytes.NewReader(data),
+		}, nil
+	}
+
+	entries := m.readDir(name)
+	if entries == nil {
+		return nil, &fs.PathError{Op: "open", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return &mapDir{
+		info:    mapFileInfo{name: path.Base(name), dir: true},
+		entries: entries,
+	}, nil
+}
+
+// ReadFile implements fs.ReadFileFS.
+func (m mapFS) ReadFile(name string) ([]byte, error) {
+	if !fs.ValidPath(name) {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrInvalid}
+	}
+	data, ok := m[name]
+	if !ok {
+		return nil, &fs.PathError{Op: "read", Path: name, Err: fs.ErrNotExist}
+	}
+
+	return append([]byte(nil), data...), nil
+}
+
+// readDir returns the entries of the directory dir in the order of the name, or nil if dir does not exist.
+func (m mapFS) readDir(dir string) []fs.DirEntry {
+	prefix := dir + "/"
+	if dir == "." {
+		prefix = ""
+	}
+
+	seen := make(map[string]bool)
+	entries := []fs.DirEntry{}
+	for name, data := range m {
+		if !strings.HasPrefix(name, prefix) {
+			continue
+		}
+		rest := strings.TrimPrefix(name, prefix)
+		elem, _, isDir := strings.Cut(rest, "/")
+		if seen[elem] {
+			continue
+		}
+		seen[elem] = true
+		info := mapFileInfo{name: elem, dir: isDir}
+		if !isDir {
+			info.size = int64(len(data))
+		}
+		entries = append(entries, info)
+	}
+	if len(entries) == 0 && dir != "." {
+		return nil
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	return entries
+}
+
+// mapFileInfo is the fs.FileInfo and fs.DirEntry of mapFS.
+type mapFileInfo struct {
+	name string
+	size int64
+	dir  bool
+}
+
+var (
+	_ fs.FileInfo = mapFileInfo{}
+	_ fs.DirEntry = mapFileInfo{}
+)
+
+func (fi mapFileInfo) Name() string               { return fi.name }
+func (fi mapFileInfo) Size() int64                { return fi.size }
+func (fi mapFileInfo) ModTime() time.Time         { return time.Time{} }
+func (fi mapFileInfo) IsDir() bool                { return fi.dir }
+func (fi mapFileInfo) Sys() interface{}           { return nil }
+func (fi mapFileInfo) Type() fs.FileMode          { return fi.Mode().Type() }
+func (fi mapFileInfo) Info() (fs.FileInfo, error) { return fi, nil }
+
+// Mode returns the read-only file mode.
+func (fi mapFileInfo) Mode() fs.FileMode {
+	if fi.dir {
+		return fs.ModeDir | 0555
+	}
+	return 0444
+}
+
+// mapFile is the opened file of mapFS.
+type mapFile struct {
+	info mapFileInfo
+	*bytes.Reader
+}
+
+var _ io.ReaderAt = (*mapFile)(nil)
+
+func (f *mapFile) Stat() (fs.FileInfo, error) { return f.info, nil }
+func (f *mapFile) Close() error               { return nil }
+
+// mapDir is the opened directory of mapFS.
+type mapDir struct {
+	info    mapFileInfo
+	entries []fs.DirEntry
+	offset  int
+}
+
+var _ fs.ReadDirFile = (*mapDir)(nil)
+
+func (d *mapDir) Stat() (fs.FileInfo, error) { return d.info, nil }
+func (d *mapDir) Close() error               { return nil }
+
+// Read implements fs.File, which fails for the directory.
+func (d *mapDir) Read([]byte) (int, error) {
+	return 0, &fs.PathError{Op: "read", Path: d.info.name, Err: fs.ErrInvalid}
+}
+
+// ReadDir implements fs.ReadDirFile.
+func (d *mapDir) ReadDir(n int) ([]fs.DirEntry, error) {
+	rest := d.entries[d.offset:]
+	if n <= 0 {
+		d.offset = len(d.entries)
+		return rest, nil
+	}
+	if len(rest) == 0 {
+		return nil, io.EOF
+	}
+	if n > len(rest) {
+		n = len(rest)
+	}
+	d.offset += n
+
+	return rest[:n], nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestBuildTypeModel(t *testing.T) {
+	const spec = `openapi: 3.0.3
+info:
+  title: Kinds
+  version: 1.0.0
+paths: {}
+components:
+  schemas:
+    Pet:
+      type: object
+      properties:
+        id:
+          type: integer
+          format: int64
+    Pets:
+      type: array
+      maxItems: 10
+      items:
+        $ref: '#/components/schemas/Pet'
+    Status:
+      type: string
+      enum: [available, sold-out]
+    Level:
+      type: integer
+      enum: [-1, 2]
+    Labels:
+      type: object
+      additionalProperties:
+        type: string
+    Name:
+      type: string
+      minLength: 1
+    Score:
+      type: number
+      format: double
+    Animal:
+      $ref: '#/components/schemas/Pet'
+    Anything: {}
+`
+	g, err := NewFromReader(strings.NewReader(spec), WithPackageName("kinds"), WithTypeCheck())
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := g.GenerateFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string][]string{
+		"model_pet.go":      {"ID int64 `json:\"id,omitempty\"`"},
+		"model_pets.go":     {"type Pets []*Pet\n", "if len(*p) > 10 {", "errs = errs.appendPrefixed(\"/\"+strconv.Itoa(i), v.Validate())"},
+		"model_status.go":   {"type Status string\n", "StatusAvailable Status = \"available\"", "StatusSoldOut   Status = \"sold-out\"", "switch string(*s) {"},
+		"model_level.go":    {"type Level int32\n", "LevelMinus1 Level = -1", "Level2      Level = 2"},
+		"model_labels.go":   {"type Labels map[string]string\n"},
+		"model_name.go":     {"type Name string\n", "if utf8.RuneCountInString(string(*n)) < 1 {"},
+		"model_score.go":    {"type Score float64\n"},
+		"model_animal.go":   {"type Animal = Pet\n"},
+		"model_anything.go": {"type Anything = interface{}\n"},
+	}
+	for name, wants := range tests {
+		src := string(files[name])
+		for _, want := range wants {
+			if !strings.Contains(src, want) {
+				t.Errorf("%s does not contain %q:\n%s", name, want, src)
+			}
+		}
+	}
+	if strings.Contains(string(files["model_anything.go"]), "Validate") {
+		t.Errorf("the type alias has Validate method:\n%s", files["model_anything.go"])
+	}
+}
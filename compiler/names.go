@@ -0,0 +1,336 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"fmt"
+	"go/token"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode"
+
+	"github.com/zchee/go-openapi-tools/ir"
+)
+
+// predeclared is the set of Go predeclared identifiers, which are valid but shadowed by the declaration.
+var predeclared = map[string]bool{
+	"any": true, "bool": true, "byte": true, "comparable": true, "complex64": true, "complex128": true, "error": true,
+	"float32": true, "float64": true, "int": true, "int8": true, "int16": true, "int32": true, "int64": true,
+	"rune": true, "string": true, "uint": true, "uint8": true, "uint16": true, "uint32": true, "uint64": true,
+	"uintptr": true, "true": true, "false": true, "iota": true, "nil": true, "append": true, "cap": true,
+	"close": true, "complex": true, "copy": true, "delete": true, "imag": true, "len": true, "make": true,
+	"new": true, "panic": true, "print": true, "println": true, "real": true, "recover": true, "clear": true,
+	"min": true, "max": true,
+}
+
+// packageScope is the scope of the package level identifiers, which are the public API of the generated package.
+const packageScope = "package"
+
+// packageDecls is the package level identifiers which always declared by the generated package.
+var packageDecls = []string{
+	"APIVersion", "CallInfo", "DefaultErrorHandler", "ErrorHandlerFunc", "Handler", "HandlerFromMux", "HandlerResponse",
+	"Interceptor", "NewService", "ParamError", "ResponseError", "SchemaDescriptor", "ServerInterface", "ServerResponse",
+	"Service", "UserAgent", "ValidationError", "ValidationErrors", "ValidationMiddleware",
+}
+
+// webhookDecls is the package level identifiers which declared by the generated package if the schema has the
+// webhooks or callbacks.
+var webhookDecls = []string{
+	"DefaultWebhookErrorHandler", "ErrInvalidSignature", "HMACSignatureVerifier", "SignatureVerifier", "WebhookError",
+	"WebhookErrorHandlerFunc", "WebhookOption", "WithPayloadValidation", "WithSignatureVerifier", "WithWebhookErrorHandler",
+}
+
+// callReserved is the identifiers of the Call type fields and methods, and the locals and packages used by the
+// constructor and the query setters.
+var callReserved = []string{"s", "header", "params", "body", "r", "c", "fmt", "http", "url", "Do", "Validate"}
+
+// responseReserved is the identifiers of the embedded ServerResponse of the Call response type.
+var responseReserved = []string{"ServerResponse", "HTTPStatusCode", "Header", "Body"}
+
+// handlerReserved is the identifiers of the arguments, locals and packages used by the server handlers.
+var handlerReserved = []string{
+	"a", "w", "r", "c", "e", "v", "vs", "ctx", "err", "params", "body",
+	"context", "errors", "fmt", "http", "json", "strconv", "strings",
+}
+
+// serviceFields is the identifiers which the service types must not use, such as the Service fields and the
+// declarations of the fake package.
+var serviceFields = []string{"BasePath", "SkipValidation", "Fake", "New", "Calls"}
+
+// Rename represents the Go identifier which renamed from the preferred one, to be valid and unique in the scope.
+type Rename struct {
+	Scope  string // scope of the identifier, such as "package" or "model Pet"
+	Name   string // name of the identifier origin, such as the schema or parameter name
+	From   string // preferred identifier
+	To     string // resolved identifier
+	Reason string
+}
+
+// String returns a string representation of the Rename.
+func (r *Rename) String() string {
+	return fmt.Sprintf("%s: %s: renamed %s to %s, %s", r.Scope, r.Name, r.From, r.To, r.Reason)
+}
+
+// Renames returns the identifiers renamed in the generation, in the order of the scope and name.
+func (g *Generator) Renames() []*Rename {
+	renames := append([]*Rename(nil), g.renames...)
+	sort.SliceStable(renames, func(i, j int) bool {
+		if renames[i].Scope != renames[j].Scope {
+			return renames[i].Scope < renames[j].Scope
+		}
+		return renames[i].Name < renames[j].Name
+	})
+
+	return renames
+}
+
+// namespace resolves the valid and unique Go identifiers in a scope.
+type namespace struct {
+	g      *Generator
+	scope  string
+	used   map[string]bool   // identifiers in use
+	idents map[string]string // name to the resolved identifier
+}
+
+// namespace returns the namespace of the scope. The reserved identifiers are used when the namespace is created.
+func (g *Generator) namespace(scope string, reserved ...string) *namespace {
+	if ns, ok := g.namespaces[scope]; ok {
+		return ns
+	}
+
+	ns := &namespace{
+		g:      g,
+		scope:  scope,
+		used:   make(map[string]bool),
+		idents: make(map[string]string),
+	}
+	for _, ident := range reserved {
+		ns.used[ident] = true
+	}
+	g.namespaces[scope] = ns
+
+	return ns
+}
+
+// ident returns the identifier of name, which is the first candidate which is valid and not used in the scope.
+//
+// If all candidates are used, the first candidate is numbered. The same name always returns the same identifier.
+func (ns *namespace) ident(name string, candidates ...string) string {
+	return ns.identAvoid(name, nil, candidates...)
+}
+
+// identAvoid is like ident, but also does not use the avoid identifiers for name.
+func (ns *namespace) identAvoid(name string, avoid []string, candidates ...string) string {
+	if ident, ok := ns.idents[name]; ok {
+		return ident
+	}
+
+	from := candidates[0]
+	ident, reason := validIdent(from)
+	if ns.used[ident] || contains(ident, avoid) {
+		reason = "conflicts with " + ident
+		found := false
+		for _, candidate := range candidates[1:] {
+			if id, _ := validIdent(candidate); !ns.used[id] && !contains(id, avoid) {
+				ident, found = id, true
+				break
+			}
+		}
+		if !found {
+			base := ident
+			for i := 2; ns.used[ident] || contains(ident, avoid); i++ {
+				ident = base + strconv.Itoa(i)
+			}
+		}
+	}
+	ns.used[ident] = true
+	ns.idents[name] = ident
+
+	if ident != from {
+		ns.g.renames = append(ns.g.renames, &Rename{
+			Scope:  ns.scope,
+			Name:   name,
+			From:   from,
+			To:     ident,
+			Reason: reason,
+		})
+	}
+
+	return ident
+}
+
+// validIdent returns the valid Go identifier of ident, and the reason if changed.
+//
+// The invalid characters are removed, and the keywords and predeclared identifiers are suffixed by "_".
+func validIdent(ident string) (string, string) {
+	switch {
+	case token.IsKeyword(ident):
+		return ident + "_", "Go keyword"
+	case predeclared[ident]:
+		return ident + "_", "predeclared identifier"
+	case token.IsIdentifier(ident):
+		return ident, ""
+	}
+
+	exported := false
+	var sb strings.Builder
+	for i, r := range ident {
+		if i == 0 {
+			exported = unicode.IsUpper(r)
+		}
+		if r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+		}
+	}
+	s := sb.String()
+	if s == "" || unicode.IsDigit([]rune(s)[0]) {
+		prefix := "x"
+		if exported || s == "" {
+			prefix = "X"
+		}
+		s = prefix + s
+	}
+	if token.IsKeyword(s) || predeclared[s] {
+		s += "_"
+	}
+
+	return s, "invalid identifier"
+}
+
+// resolveNames resolves the package level identifiers of the models and services.
+//
+// The models keep those names in priority, and the services which conflict are suffixed by "Service".
+func (g *Generator) resolveNames() {
+	for _, m := range g.api.Models {
+		g.modelType(m.Name)
+	}
+	for _, svc := range g.api.Services {
+		g.serviceType(svc)
+	}
+}
+
+// modelKey returns the package namespace name of the model.
+func modelKey(name string) string {
+	return "#/components/schemas/" + name
+}
+
+// serviceKey returns the package namespace name of the service.
+func serviceKey(svc *ir.Service) string {
+	return "tag " + svc.Name
+}
+
+// packageNamespace returns the namespace of the package level identifiers.
+func (g *Generator) packageNamespace() *namespace {
+	decls := packageDecls
+	if g.generatesWebhooks() {
+		decls = append(append([]string(nil), packageDecls...), webhookDecls...)
+	}
+
+	return g.namespace(packageScope, decls...)
+}
+
+// depunct returns the Go identifier of ident by Depunct, which keeps the initialisms of WithInitialisms.
+func (g *Generator) depunct(ident string, needCap bool) string {
+	return depunct(ident, needCap, g.initialisms)
+}
+
+// modelType returns the Go type name of the schema name.
+func (g *Generator) modelType(name string) string {
+	return g.packageNamespace().ident(modelKey(name), g.depunct(name, true))
+}
+
+// serviceType returns the Go type name of the service.
+func (g *Generator) serviceType(svc *ir.Service) string {
+	name := g.depunct(svc.Name, true)
+	return g.packageNamespace().identAvoid(serviceKey(svc), serviceFields, name, name+"Service")
+}
+
+// webhookTypes returns the function type and the handler constructor names of the receiver of the webhook.
+//
+// base is the name from the operation ID, or the webhook or callback name.
+func (g *Generator) webhookTypes(w *ir.Webhook, base string) (funcType, handler string) {
+	suffix := "Webhook"
+	if w.Callback != nil {
+		suffix = "Callback"
+	}
+	pointer := webhookPointer(w)
+	funcType = g.declName("webhook func "+pointer, base+suffix+"Func")
+	handler = g.declName("webhook handler "+pointer, strings.TrimSuffix(funcType, "Func")+"Handler")
+
+	return funcType, handler
+}
+
+// declName returns the package level identifier of name, which derived from the other identifiers such as the Call
+// type of the operation.
+func (g *Generator) declName(name, ident string) string {
+	return g.packageNamespace().ident(name, ident)
+}
+
+// receiverName returns the receiver name of the typeName type.
+func receiverName(typeName string) string {
+	for _, r := range typeName {
+		if unicode.IsLetter(r) {
+			return string(unicode.ToLower(r))
+		}
+		break
+	}
+
+	return "x"
+}
+
+// callParam returns the field name of the parameter of the methType Call, which is also the argument name of the
+// constructor and the query setter.
+func (g *Generator) callParam(methType string, param *ir.Param) string {
+	ns := g.namespace("call "+methType, callReserved...)
+	return ns.ident(param.In+" "+param.Name, NormalizeParam(g.depunct(param.Name, false)))
+}
+
+// callSetter returns the method name of the methType Call which sets the query parameter.
+func (g *Generator) callSetter(methType string, param *ir.Param) string {
+	ns := g.namespace("call "+methType, callReserved...)
+	return ns.ident("setter "+param.Name, g.depunct(g.callParam(methType, param), true))
+}
+
+// responseField returns the field or method name of the respType Call response.
+//
+// name is the property name, or the "header " prefixed header name for the header accessor methods.
+func (g *Generator) responseField(respType, name, ident string) string {
+	return g.namespace("response "+respType, responseReserved...).ident(name, ident)
+}
+
+// modelField returns the field or method name of the modelName model.
+//
+// property is the property name, or the "Get " prefixed property name for the getter methods.
+func (g *Generator) modelField(modelName, property, ident string) string {
+	return g.namespace("model "+g.modelType(modelName), "Validate").ident(property, ident)
+}
+
+// handlerParam returns the argument name of the path parameter of the server handler of the operation.
+func (g *Generator) handlerParam(opName, param string) string {
+	ns := g.namespace("handler "+opName, handlerReserved...)
+	return ns.ident(param, NormalizeParam(g.depunct(param, false)))
+}
+
+// paramsField returns the field name of the parameter of the operation Params struct.
+func (g *Generator) paramsField(opName, in, param string) string {
+	return g.namespace("params "+opName).ident(in+" "+param, g.depunct(param, true))
+}
+
+// serverMethod returns the method name of ServerInterface which handles op, which is the operation name unless it
+// conflicts with the other handlers.
+func (g *Generator) serverMethod(op *ir.Operation) string {
+	return g.namespace("ServerInterface").ident(operationKey(op), g.operationName(op))
+}
+
+// adapterMethod returns the method name of serverAdapter which handles the operation.
+func (g *Generator) adapterMethod(opName string) string {
+	ns := g.namespace("serverAdapter", "si", "errorHandler", "writeResponse")
+	return ns.ident(opName, lowerFirst(opName))
+}
+
+// fakeField returns the field name of the fake service which holds the stub of the method.
+func (g *Generator) fakeField(svcName, method string) string {
+	return g.namespace("fake "+svcName, "fake", "mu", "intercept").ident(method, lowerFirst(method))
+}
@@ -0,0 +1,122 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/zchee/go-openapi-tools/diag"
+)
+
+func TestValidIdent(t *testing.T) {
+	tests := map[string]struct {
+		ident  string
+		want   string
+		reason string
+	}{
+		"Valid":       {ident: "PetID", want: "PetID"},
+		"Keyword":     {ident: "type", want: "type_", reason: "Go keyword"},
+		"Predeclared": {ident: "string", want: "string_", reason: "predeclared identifier"},
+		"Clear":       {ident: "clear", want: "clear_", reason: "predeclared identifier"},
+		"Min":         {ident: "min", want: "min_", reason: "predeclared identifier"},
+		"Max":         {ident: "max", want: "max_", reason: "predeclared identifier"},
+		"Invalid":     {ident: "pet-id", want: "petid", reason: "invalid identifier"},
+		"Digit":       {ident: "1st", want: "x1st", reason: "invalid identifier"},
+		"Empty":       {ident: "$", want: "X", reason: "invalid identifier"},
+		"KeywordLeft": {ident: "func()", want: "func_", reason: "invalid identifier"},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, reason := validIdent(tt.ident)
+			if got != tt.want || reason != tt.reason {
+				t.Fatalf("validIdent(%q) = (%q, %q), want (%q, %q)", tt.ident, got, reason, tt.want, tt.reason)
+			}
+		})
+	}
+}
+
+func TestNamespaceIdent(t *testing.T) {
+	g := &Generator{namespaces: make(map[string]*namespace)}
+	ns := g.namespace("params", "ctx")
+
+	tests := []struct {
+		name       string
+		candidates []string
+		want       string
+	}{
+		{name: "query foo_bar", candidates: []string{"fooBar"}, want: "fooBar"},
+		{name: "query fooBar", candidates: []string{"fooBar"}, want: "fooBar2"},
+		{name: "query foo_bar", candidates: []string{"other"}, want: "fooBar"}, // the same name is stable
+		{name: "query ctx", candidates: []string{"ctx", "ctxParam"}, want: "ctxParam"},
+		{name: "query type", candidates: []string{"type"}, want: "type_"},
+	}
+	for _, tt := range tests {
+		if got := ns.ident(tt.name, tt.candidates...); got != tt.want {
+			t.Errorf("ident(%q, %q) = %q, want %q", tt.name, tt.candidates, got, tt.want)
+		}
+	}
+	if got := len(g.Renames()); got != 3 {
+		t.Errorf("Renames() has %d renames, want 3", got)
+	}
+}
+
+func TestOperationNamesValidation(t *testing.T) {
+	tests := map[string]struct {
+		names   map[string]string
+		wantErr string
+	}{
+		"Valid":       {names: map[string]string{"listPets": "AllPets"}},
+		"Keyword":     {names: map[string]string{"listPets": "func"}, wantErr: "operation name func of GET /pets is not valid, Go keyword"},
+		"Invalid":     {names: map[string]string{"GET /pets": "All-Pets"}, wantErr: "operation name All-Pets of GET /pets is not valid, invalid identifier"},
+		"Unexported":  {names: map[string]string{"listPets": "allPets"}, wantErr: "operation name allPets of GET /pets is not exported"},
+		"Predeclared": {names: map[string]string{"listPets": "max"}, wantErr: "operation name max of GET /pets is not valid, predeclared identifier"},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			g, err := New(SchemaNameOpenAPI, "petstore", filepath.Join("testdata", "petstore.yaml"), WithOperationNames(tt.names))
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = g.GenerateFiles()
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Fatal(err)
+			case tt.wantErr != "" && (err == nil || !strings.Contains(err.Error(), tt.wantErr)):
+				t.Fatalf("GenerateFiles() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRenameSeverity(t *testing.T) {
+	g, err := New(SchemaNameOpenAPI, "petstore", filepath.Join("testdata", "petstore.yaml"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := g.GenerateFiles(); err != nil {
+		t.Fatal(err)
+	}
+
+	found := false
+	for _, d := range g.Diagnostics() {
+		if !strings.Contains(d.Msg, "renamed Pets to PetsService") {
+			continue
+		}
+		found = true
+		if d.Severity != diag.Warning {
+			t.Errorf("%s: severity = %v, want %v", d.Msg, d.Severity, diag.Warning)
+		}
+	}
+	if !found {
+		t.Fatal("the rename of the pets tag is not reported")
+	}
+}
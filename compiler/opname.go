@@ -0,0 +1,197 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"fmt"
+	"go/token"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+
+	"github.com/zchee/go-openapi-tools/diag"
+	"github.com/zchee/go-openapi-tools/ir"
+)
+
+// WithOperationNames overrides the Go method names of the operations.
+//
+// The key of names is the operation ID, or the HTTP method and path such as "GET /pets/{id}" which also names the
+// operation without the operation ID.
+func WithOperationNames(names map[string]string) Option {
+	return func(g *Generator) {
+		for id, name := range names {
+			g.operationNames[id] = name
+		}
+	}
+}
+
+// WithoutGetPrefixTrim keeps the "Get" prefix of the method names from the operation IDs, such as GetPet.
+//
+// By default, the "Get" prefix is trimmed by Go idiom, such as "getPet" to Pet.
+func WithoutGetPrefixTrim() Option {
+	return func(g *Generator) {
+		g.keepGetPrefix = true
+	}
+}
+
+// operationKey returns the HTTP method and path of op, such as "GET /pets/{id}".
+func operationKey(op *ir.Operation) string {
+	return op.Method + " " + op.Path
+}
+
+// operationName returns the Go method name of op, which resolved by resolveOperationNames.
+func (g *Generator) operationName(op *ir.Operation) string {
+	return g.opNames[op]
+}
+
+// resolveOperationNames resolves the Go method names of all operations.
+//
+// The names from the overrides and the operation IDs must be unique, it returns the error if not. The operations
+// which have neither are named from the HTTP method and path, and numbered in the order of the path and HTTP method
+// if the name is already used.
+func (g *Generator) resolveOperationNames() error {
+	g.opNames = make(map[*ir.Operation]string)
+
+	owners := make(map[string]*ir.Operation) // method name to the operation
+	var unnamed []*ir.Operation
+	for _, op := range g.api.Operations {
+		name, ok, err := g.declaredOperationName(op)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			unnamed = append(unnamed, op)
+			continue
+		}
+		if prev, ok := owners[name]; ok {
+			return diag.Errorf(operationPointer(op), "operations %s and %s have the same method name %s, rename either by the operation names", operationKey(prev), operationKey(op), name)
+		}
+		owners[name] = op
+		g.opNames[op] = name
+	}
+
+	for _, op := range unnamed {
+		base := g.synthesizeOperationName(op.Method, op.Path)
+		name := base
+		for i := 2; owners[name] != nil; i++ {
+			name = fmt.Sprintf("%s%d", base, i)
+		}
+		owners[name] = op
+		g.opNames[op] = name
+	}
+
+	return nil
+}
+
+// declaredOperationName returns the method name of op from the overrides or the operation ID, if any.
+//
+// The name of the overrides must be the valid exported Go identifier, it returns the error if not.
+func (g *Generator) declaredOperationName(op *ir.Operation) (string, bool, error) {
+	name, ok := g.operationNames[operationKey(op)]
+	if !ok && op.ID != "" {
+		name, ok = g.operationNames[op.ID]
+	}
+	if ok {
+		if ident, reason := validIdent(name); ident != name {
+			return "", false, diag.Errorf(operationPointer(op), "operation name %s of %s is not valid, %s", name, operationKey(op), reason)
+		}
+		if !token.IsExported(name) {
+			return "", false, diag.Errorf(operationPointer(op), "operation name %s of %s is not exported", name, operationKey(op))
+		}
+		return name, true, nil
+	}
+	if op.ID == "" {
+		return "", false, nil
+	}
+
+	name = g.depunct(op.ID, true)
+	if !g.keepGetPrefix {
+		name = trimGetPrefix(name)
+	}
+
+	return name, true, nil
+}
+
+// trimGetPrefix trims the "Get" prefix of name by Go idiom, only if the rest is a word such as "GetPet".
+func trimGetPrefix(name string) string {
+	rest := strings.TrimPrefix(name, "Get")
+	if rest == name || rest == "" {
+		return name
+	}
+	if r, _ := utf8.DecodeRuneInString(rest); !unicode.IsUpper(r) {
+		return name // such as "Getaway"
+	}
+
+	return rest
+}
+
+// synthesizeOperationName returns the method name from the HTTP method and path, such as GetPet from "GET /pets/{id}".
+//
+// The path segment followed by the path parameter is singularized.
+func (g *Generator) synthesizeOperationName(method, path string) string {
+	var sb strings.Builder
+	sb.WriteString(g.depunct(strings.ToLower(method), true))
+
+	segments := strings.Split(strings.Trim(path, "/"), "/")
+	named := false
+	for i, seg := range segments {
+		seg = trimPathParams(seg)
+		if seg == "" {
+			continue
+		}
+		if i+1 < len(segments) && isPathParam(segments[i+1]) {
+			seg = singular(seg)
+		}
+		sb.WriteString(g.depunct(seg, true))
+		named = true
+	}
+	if !named {
+		sb.WriteString("Root")
+	}
+
+	return sb.String()
+}
+
+// isPathParam reports whether the path segment is a path parameter such as "{id}".
+func isPathParam(seg string) bool {
+	return strings.HasPrefix(seg, "{") && strings.HasSuffix(seg, "}")
+}
+
+// trimPathParams removes the path parameters from the path segment, such as "{id}" of "{id}.json".
+func trimPathParams(seg string) string {
+	var sb strings.Builder
+	for {
+		start := strings.Index(seg, "{")
+		if start == -1 {
+			break
+		}
+		end := strings.Index(seg[start:], "}")
+		if end == -1 {
+			break
+		}
+		sb.WriteString(seg[:start])
+		seg = seg[start+end+1:]
+	}
+	sb.WriteString(seg)
+
+	return strings.Trim(sb.String(), "-._")
+}
+
+// singular returns the singular form of the English plural noun by the simple rules.
+func singular(noun string) string {
+	lower := strings.ToLower(noun)
+	switch {
+	case strings.HasSuffix(lower, "ies") && len(noun) > 3:
+		return noun[:len(noun)-3] + "y"
+	case strings.HasSuffix(lower, "sses"), strings.HasSuffix(lower, "xes"),
+		strings.HasSuffix(lower, "ches"), strings.HasSuffix(lower, "shes"):
+		return noun[:len(noun)-2]
+	case strings.HasSuffix(lower, "ss"), strings.HasSuffix(lower, "us"), strings.HasSuffix(lower, "is"):
+		return noun
+	case strings.HasSuffix(lower, "s") && len(noun) > 1:
+		return noun[:len(noun)-1]
+	default:
+		return noun
+	}
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// FileWriter writes the generated files, such as to the directory or the in-memory filesystem.
+type FileWriter interface {
+	// WriteFile writes data to the file name, which is the slash separated path relative to the output root such
+	// as "fake/fake.go".
+	WriteFile(name string, data []byte) error
+}
+
+// DirWriter is the FileWriter which writes the files under the directory.
+type DirWriter string
+
+var _ FileWriter = DirWriter("")
+
+// WriteFile implements FileWriter.
+func (d DirWriter) WriteFile(name string, data []byte) error {
+	path := filepath.Join(string(d), filepath.FromSlash(name))
+	if dir := filepath.Dir(path); dir != "." {
+		if err := os.MkdirAll(dir, 0755); err != nil {
+			return fmt.Errorf("failed to MkdirAll %s: %w", dir, err)
+		}
+	}
+	if err := os.WriteFile(path, data, 0666); err != nil {
+		return fmt.Errorf("failed to write %s: %w", path, err)
+	}
+
+	return nil
+}
+
+// MapWriter is the FileWriter which holds the files in memory, keyed by the file name.
+type MapWriter map[string][]byte
+
+var _ FileWriter = MapWriter(nil)
+
+// WriteFile implements FileWriter.
+func (m MapWriter) WriteFile(name string, data []byte) error {
+	m[name] = append([]byte(nil), data...)
+	return nil
+}
+
+// GenerateFiles generates the API from openapi3.T schema, and returns the files in memory without writing any files.
+//
+// The key is the slash separated path relative to the output directory such as "fake/fake.go".
+func (g *Generator) GenerateFiles() (map[string][]byte, error) {
+	files := make(MapWriter)
+	if err := g.GenerateTo(files); err != nil {
+		return nil, err
+	}
+
+	return files, nil
+}
+
+// GenerateFS is like GenerateFiles, but returns the files as the read-only filesystem.
+func (g *Generator) GenerateFS() (fs.FS, error) {
+	files, err := g.GenerateFiles()
+	if err != nil {
+		return nil, err
+	}
+
+	return mapFS(files), nil
+}
+
+// GenerateTo generates the API from openapi3.T schema, and writes the files to w in the order of the file name.
+//
+// Unlike Generate, it never removes the stale files even if WithClean is given.
+func (g *Generator) GenerateTo(w FileWriter) error {
+	if err := g.generate(); err != nil {
+		return fmt.Errorf("failed to generate: %w", err)
+	}
+
+	return g.writeFiles(w)
+}
+
+// writeFiles writes the generated files to w in the order of the file name.
+func (g *Generator) writeFiles(w FileWriter) error {
+	for _, name := range SortedMapKeys(g.files) {
+		if err := w.WriteFile(filepath.ToSlash(name), g.files[name]); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,44 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"bytes"
+	"io/fs"
+	"path/filepath"
+	"testing"
+	"testing/fstest"
+)
+
+func TestGenerateFS(t *testing.T) {
+	g, err := New(SchemaNameOpenAPI, "petstore", filepath.Join("testdata", "petstore.yaml"), WithFake("example.com/petstore"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	fsys, err := g.GenerateFS()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := fstest.TestFS(fsys, "client.go", "doc.go", "fake/fake.go", ".oapi-generator-manifest"); err != nil {
+		t.Fatal(err)
+	}
+
+	// the same Generator generates the same files again
+	files, err := g.GenerateFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for name, want := range files {
+		got, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(got, want) {
+			t.Errorf("%s differs from the previous generation", name)
+		}
+	}
+	if renames := g.Renames(); len(renames) != 1 {
+		t.Errorf("Renames() = %v, want the rename of the pets tag only", renames)
+	}
+}
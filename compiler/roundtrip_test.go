@@ -0,0 +1,77 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"io/fs"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+)
+
+// TestGoldenRoundTrip runs the tests of testdata/roundtrip against the generated golden package, which serves and
+// calls the generated code through net/http/httptest. The tests of the fake package are under the fake directory.
+func TestGoldenRoundTrip(t *testing.T) {
+	if testing.Short() {
+		t.Skip("skipping the go test of the generated package in short mode")
+	}
+	goBin, err := exec.LookPath("go")
+	if err != nil {
+		t.Skipf("go command is not found: %v", err)
+	}
+
+	root, err := filepath.Abs("..")
+	if err != nil {
+		t.Fatal(err)
+	}
+	sum, err := os.ReadFile(filepath.Join(root, "go.sum"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	files := readGolden(t, filepath.Join("testdata", "petstore"))
+	files["go.mod"] = []byte("module example.com/petstore\n\ngo 1.22\n\n" +
+		"require github.com/zchee/go-openapi-tools v0.0.0\n\n" +
+		"replace github.com/zchee/go-openapi-tools => " + filepath.ToSlash(root) + "\n")
+	files["go.sum"] = sum
+
+	testsDir := filepath.Join("testdata", "roundtrip")
+	err = filepath.WalkDir(testsDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return err
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		rel, err := filepath.Rel(testsDir, path)
+		if err != nil {
+			return err
+		}
+		files[filepath.ToSlash(rel)] = data
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir := t.TempDir()
+	for name, data := range files {
+		path := filepath.Join(dir, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, data, 0666); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cmd := exec.Command(goBin, "test", "./...")
+	cmd.Dir = dir
+	cmd.Env = append(os.Environ(), "GOFLAGS=-mod=mod", "GOWORK=off")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		t.Fatalf("go test of the generated package failed: %v\n%s", err, out)
+	}
+}
@@ -0,0 +1,221 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"net/http"
+	"sort"
+	"strings"
+
+	"github.com/zchee/go-openapi-tools/ir"
+)
+
+// goType returns the Go type of t.
+//
+// The $ref schema is resolved to the generated model name.
+func (g *Generator) goType(t *ir.Type) string {
+	if t == nil {
+		return "interface{}"
+	}
+
+	switch t.Kind {
+	case ir.Ref:
+		return g.modelType(t.Name)
+
+	case ir.Slice:
+		return "[]" + g.goType(t.Elem)
+
+	default:
+		if typ, ok := primitiveType(t); ok {
+			return typ
+		}
+		return "interface{}"
+	}
+}
+
+// serverParams returns the parameters of op grouped by location, name is the method name of the handler.
+func (g *Generator) serverParams(name string, op *ir.Operation) map[string][]*ParamData {
+	params := make(map[string][]*ParamData, 4)
+	for _, param := range op.Params {
+		p := &ParamData{
+			Name:     param.Name,
+			In:       param.In,
+			Type:     g.goType(param.Type),
+			Required: param.Required,
+		}
+		p.Parse = parseFuncs[p.Type]
+		if param.Type != nil && param.Type.Kind == ir.Slice {
+			elem := g.goType(param.Type.Elem)
+			p.Elem = &ParamData{Name: param.Name, In: param.In, Type: elem, Parse: parseFuncs[elem]}
+			p.Split = param.In == ir.InHeader || (param.In == ir.InQuery && !param.Explode)
+		}
+		switch param.In {
+		case ir.InPath:
+			p.Field = g.handlerParam(name, param.Name)
+		default:
+			p.Field = g.paramsField(name, param.In, param.Name)
+		}
+		params[param.In] = append(params[param.In], p)
+	}
+
+	for in := range params {
+		sort.SliceStable(params[in], func(i, j int) bool { return params[in][i].Name < params[in][j].Name })
+	}
+
+	return params
+}
+
+// servePattern returns the Go 1.22 http.ServeMux pattern of method and path.
+//
+// The path parameter names are replaced to Go identifiers, because the wildcard names must be valid Go identifiers.
+func servePattern(method, path string, pathParams []*ParamData) string {
+	for _, p := range pathParams {
+		path = strings.ReplaceAll(path, "{"+p.Name+"}", "{"+p.Field+"}")
+	}
+
+	return method + " " + path
+}
+
+// parseFuncs is the map of Go type to the format of the parse expression of the string value.
+var parseFuncs = map[string]string{
+	"int32":   "strconv.ParseInt(%s, 10, 32)",
+	"int64":   "strconv.ParseInt(%s, 10, 64)",
+	"float32": "strconv.ParseFloat(%s, 32)",
+	"float64": "strconv.ParseFloat(%s, 64)",
+	"bool":    "strconv.ParseBool(%s)",
+}
+
+// buildServer returns the template data of the ServerInterface, typed responses and the net/http adapter which
+// mounts on http.ServeMux.
+func (g *Generator) buildServer() *ServerData {
+	server := new(ServerData)
+	for _, op := range g.api.Operations {
+		name := g.serverMethod(op)
+		params := g.serverParams(name, op)
+		h := &HandlerData{
+			Name:       name,
+			Method:     op.Method,
+			Path:       op.Path,
+			Summary:    sentence(op.Summary),
+			PathParams: params[ir.InPath],
+			Params:     g.serverParamFields(params),
+			BodyType:   g.requestBodyType(op),
+			StatusCode: defaultStatusCode(op),
+		}
+		h.BodyRequired = h.BodyType != "" && op.Body.Required
+		h.Pattern = servePattern(h.Method, h.Path, h.PathParams)
+		if len(h.Params) > 0 {
+			h.ParamsType = g.declName("params "+name, name+"Params")
+			g.declareSource(h.ParamsType, operationPointer(op))
+		}
+		server.Handlers = append(server.Handlers, h)
+	}
+	ops := g.api.Operations
+
+	// declares the typed response constructors after the params types, and the adapter methods at last
+	for i, h := range server.Handlers {
+		h.Responses = g.serverResponses(h.Name, ops[i])
+		for _, resp := range h.Responses {
+			g.declareSource(resp.Func, operationPointer(ops[i]))
+		}
+	}
+	for i, h := range server.Handlers {
+		h.Adapter = g.adapterMethod(h.Name)
+		g.declareSource("serverAdapter."+h.Adapter, operationPointer(ops[i]))
+	}
+
+	return server
+}
+
+// serverResponses returns the typed response constructors of the operation, sorted by the status code.
+//
+// The range status codes such as 2XX are ignored.
+func (g *Generator) serverResponses(name string, op *ir.Operation) []*ResponseData {
+	var responses []*ResponseData
+	for _, resp := range op.Responses {
+		code := resp.Code
+		body := ""
+		if schema := resp.JSON(); schema != nil {
+			body = g.goType(schema)
+		}
+
+		switch code {
+		case "default":
+			responses = append(responses, &ResponseData{
+				Func:     g.declName("default response "+name, name+"DefaultResponse"),
+				BodyType: body,
+			})
+
+		default:
+			if len(code) != 3 || !IsDigit(code[0]) || !IsDigit(code[1]) || !IsDigit(code[2]) {
+				continue // ignore 2XX style range
+			}
+			responses = append(responses, &ResponseData{
+				Func:     g.declName(code+" response "+name, name+code+"Response"),
+				Code:     code,
+				Status:   http.StatusText(statusCode(code)),
+				BodyType: body,
+			})
+		}
+	}
+
+	return responses
+}
+
+// serverParamFields returns the query, header and cookie parameters of params.
+func (g *Generator) serverParamFields(params map[string][]*ParamData) []*ParamData {
+	var fields []*ParamData
+	for _, in := range []string{ir.InQuery, ir.InHeader, ir.InCookie} {
+		fields = append(fields, params[in]...)
+	}
+
+	return fields
+}
+
+// requestBodyType returns the Go type of JSON request body of op, if any.
+func (g *Generator) requestBodyType(op *ir.Operation) string {
+	schema := op.Body.JSON()
+	if schema == nil {
+		return ""
+	}
+
+	return g.goType(schema)
+}
+
+// defaultStatusCode returns the status code of the HandlerResponse of op which has no StatusCode, which is the
+// documented success (2xx) status code if op has only one, otherwise 200 OK.
+func defaultStatusCode(op *ir.Operation) int {
+	code := http.StatusOK
+	n := 0
+	for _, resp := range op.Responses {
+		if len(resp.Code) == 3 && resp.Code[0] == '2' && IsDigit(resp.Code[1]) && IsDigit(resp.Code[2]) {
+			code = statusCode(resp.Code)
+			n++
+		}
+	}
+	if n != 1 {
+		return http.StatusOK
+	}
+
+	return code
+}
+
+// statusCode parses HTTP status code string.
+func statusCode(code string) int {
+	var n int
+	for i := 0; i < len(code); i++ {
+		n = n*10 + int(code[i]-'0')
+	}
+
+	return n
+}
+
+// lowerFirst returns s with the first letter lowercased.
+func lowerFirst(s string) string {
+	if s == "" {
+		return s
+	}
+
+	return strings.ToLower(s[:1]) + s[1:]
+}
@@ -0,0 +1,109 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	stdjson "encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/zchee/go-openapi-tools/ir"
+)
+
+// TagMode is the rule which assigns the operation to the services by its tags.
+type TagMode uint8
+
+const (
+	// TagModeFirst assigns the operation to the service of the first tag.
+	TagModeFirst TagMode = iota
+
+	// TagModeAll assigns the operation to the services of all tags.
+	TagModeAll
+)
+
+const (
+	TagModeNameFirst = "first"
+	TagModeNameAll   = "all"
+)
+
+// String returns a string representation of the TagMode.
+func (m TagMode) String() string {
+	switch m {
+	case TagModeFirst:
+		return TagModeNameFirst
+	case TagModeAll:
+		return TagModeNameAll
+	default:
+		return "unknown"
+	}
+}
+
+// ParseTagMode parses the name of TagMode, one of (first, all).
+func ParseTagMode(name string) (TagMode, error) {
+	switch strings.ToLower(name) {
+	case TagModeNameFirst, "":
+		return TagModeFirst, nil
+	case TagModeNameAll:
+		return TagModeAll, nil
+	default:
+		return TagModeFirst, fmt.Errorf("unknown tag mode %q, one of (%s, %s)", name, TagModeNameFirst, TagModeNameAll)
+	}
+}
+
+// WithTagMode sets the rule which assigns the operation which has multiple tags to the services.
+//
+// The default is TagModeFirst. The operation which has the x-go-service extension is always assigned to that service.
+func WithTagMode(mode TagMode) Option {
+	return func(g *Generator) {
+		g.tagMode = mode
+	}
+}
+
+// serviceExtension is the operation extension which chooses the service of the operation.
+const serviceExtension = "x-go-service"
+
+// defaultServiceName is the service name of the operations which have no tags.
+const defaultServiceName = ir.DefaultService
+
+// serviceName returns the service name of the tag name.
+func serviceName(tag string) string {
+	return strings.TrimSuffix(strings.ReplaceAll(tag, " ", ""), "(apps)")
+}
+
+// operationServices returns the service names which the operation is assigned to.
+func (g *Generator) operationServices(op *ir.Operation) []string {
+	if name := extensionString(op.Extensions, serviceExtension); name != "" {
+		return []string{serviceName(name)}
+	}
+
+	switch {
+	case len(op.Tags) == 0:
+		return []string{defaultServiceName}
+	case g.tagMode == TagModeAll:
+		names := make([]string, 0, len(op.Tags))
+		for _, tag := range op.Tags {
+			if name := serviceName(tag); !contains(name, names) {
+				names = append(names, name)
+			}
+		}
+		return names
+	default:
+		return []string{serviceName(op.Tags[0])}
+	}
+}
+
+// extensionString returns the string value of the extension, or empty if not a string.
+func extensionString(extensions map[string]interface{}, name string) string {
+	switch v := extensions[name].(type) {
+	case string:
+		return v
+	case stdjson.RawMessage:
+		var s string
+		if err := stdjson.Unmarshal(v, &s); err == nil {
+			return s
+		}
+	}
+
+	return ""
+}
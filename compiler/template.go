@@ -0,0 +1,175 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"bytes"
+	"embed"
+	"errors"
+	"fmt"
+	goformat "go/format"
+	"go/scanner"
+	"io/fs"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"text/template"
+
+	"github.com/zchee/go-openapi-tools/diag"
+)
+
+// templatesFS is the default templates of the generated files.
+//
+//go:embed templates/*.tmpl
+var templatesFS embed.FS
+
+// Template names of the generated files.
+const (
+	docTemplate      = "doc.go.tmpl"
+	clientTemplate   = "client.go.tmpl"
+	apiTemplate      = "api.go.tmpl"
+	modelTemplate    = "model.go.tmpl"
+	serverTemplate   = "server.go.tmpl"
+	webhooksTemplate = "webhooks.go.tmpl"
+	fakeTemplate     = "fake.go.tmpl"
+	utilsTemplate    = "utils.go.tmpl"
+)
+
+// WithTemplateDir overrides the default templates by the *.tmpl files in dir.
+//
+// The file which has the same name as the default template, such as "model.go.tmpl", replaces the whole template,
+// and the {{define}} actions in any file replace the named templates, such as "check" or "operation".
+// The templates are executed with *FileData. See DefaultTemplates for the default templates.
+func WithTemplateDir(dir string) Option {
+	return func(g *Generator) {
+		g.templateDir = dir
+	}
+}
+
+// DefaultTemplates returns the default templates of the generated files, which are overridden by WithTemplateDir.
+func DefaultTemplates() fs.FS {
+	sub, err := fs.Sub(templatesFS, "templates")
+	if err != nil {
+		panic(err) // unreachable, the directory is embedded
+	}
+
+	return sub
+}
+
+// templateFuncs is the functions available in the templates.
+var templateFuncs = template.FuncMap{
+	// comment returns s as the line comments
+	"comment": func(s string) string {
+		return "// " + strings.ReplaceAll(s, "\n", "\n// ")
+	},
+	// apiName returns the API name of the title such as "Petstore API", or the title as is if it ends with "API"
+	"apiName": func(title string) string {
+		if strings.HasSuffix(title, "API") {
+			return title
+		}
+		return title + " API"
+	},
+	// quote returns s as the Go string literal
+	"quote": strconv.Quote,
+	// join concatenates elems with sep
+	"join": func(elems []string, sep string) string {
+		return strings.Join(elems, sep)
+	},
+	// dict returns the map of the key and value pairs, to pass multiple values to the template
+	"dict": func(pairs ...interface{}) (map[string]interface{}, error) {
+		if len(pairs)%2 != 0 {
+			return nil, errors.New("dict requires the key and value pairs")
+		}
+		m := make(map[string]interface{}, len(pairs)/2)
+		for i := 0; i < len(pairs); i += 2 {
+			key, ok := pairs[i].(string)
+			if !ok {
+				return nil, fmt.Errorf("dict key must be string, not %T", pairs[i])
+			}
+			m[key] = pairs[i+1]
+		}
+		return m, nil
+	},
+}
+
+// parseTemplates parses the default templates, and the override templates in the template directory, if any.
+func (g *Generator) parseTemplates() error {
+	tmpl, err := template.New("").Funcs(templateFuncs).ParseFS(templatesFS, "templates/*.tmpl")
+	if err != nil {
+		return fmt.Errorf("failed to parse default templates: %w", err)
+	}
+
+	if g.templateDir != "" {
+		files, err := filepath.Glob(filepath.Join(g.templateDir, "*.tmpl"))
+		if err != nil {
+			return fmt.Errorf("failed to find templates in %s: %w", g.templateDir, err)
+		}
+		if len(files) == 0 {
+			return fmt.Errorf("no *.tmpl files in %s", g.templateDir)
+		}
+		if tmpl, err = tmpl.ParseFiles(files...); err != nil {
+			return fmt.Errorf("failed to parse templates in %s: %w", g.templateDir, err)
+		}
+	}
+	g.templates = tmpl
+
+	return nil
+}
+
+// render executes the tmpl template with data, and stores the formatted source as the generated file name.
+//
+// The imports of the source are rewritten to the referenced packages by fixImports.
+func (g *Generator) render(name, tmpl string, data *FileData) error {
+	var buf bytes.Buffer
+	if err := g.templates.ExecuteTemplate(&buf, tmpl, data); err != nil {
+		return fmt.Errorf("failed to execute %s template: %w", tmpl, err)
+	}
+
+	src, err := formatSource(name, buf.Bytes())
+	if err != nil {
+		if pointer := g.declSource(name); pointer != "" {
+			return diag.Errorf(pointer, "%v", err)
+		}
+		return err
+	}
+	if src, err = g.fixImports(name, src); err != nil {
+		return err
+	}
+	g.files[name] = src
+	g.logger.Debug("rendered file", "file", name, "template", tmpl)
+
+	return nil
+}
+
+// formatSource formats the generated Go source of the file name.
+//
+// The error has a few lines around the syntax error of the source, to find the broken template easily.
+func formatSource(name string, src []byte) ([]byte, error) {
+	formatted, err := goformat.Source(src)
+	if err == nil {
+		return formatted, nil
+	}
+
+	var errs scanner.ErrorList
+	if !errors.As(err, &errs) || len(errs) == 0 {
+		return nil, fmt.Errorf("could not format %s: %w\n%s", name, err, src)
+	}
+	line := errs[0].Pos.Line
+
+	const around = 3
+	lines := strings.Split(string(src), "\n")
+	var sb strings.Builder
+	for i := line - around; i <= line+around; i++ {
+		if i < 1 || i > len(lines) {
+			continue
+		}
+		mark := " "
+		if i == line {
+			mark = ">"
+		}
+		fmt.Fprintf(&sb, "%s%5d\t%s\n", mark, i, lines[i-1])
+	}
+
+	return nil, fmt.Errorf("could not format %s: %w\n%s", name, err, sb.String())
+}
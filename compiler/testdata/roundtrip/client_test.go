@@ -0,0 +1,90 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package petstore
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+)
+
+// newTestService returns the Service which calls the petServer through the httptest server.
+func newTestService(t *testing.T, s *petServer) *Service {
+	t.Helper()
+
+	srv := httptest.NewServer(Handler(s))
+	t.Cleanup(srv.Close)
+
+	svc, err := NewService(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	svc.BasePath = srv.URL + "/"
+
+	return svc
+}
+
+func TestClientInventory(t *testing.T) {
+	svc := newTestService(t, new(petServer))
+
+	resp, err := svc.Store.Inventory().Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.HTTPStatusCode != http.StatusOK {
+		t.Errorf("HTTPStatusCode = %d, want %d", resp.HTTPStatusCode, http.StatusOK)
+	}
+	if got, want := string(resp.Body), "{\"available\":1}\n"; got != want {
+		t.Errorf("Body = %q, want %q", got, want)
+	}
+}
+
+func TestClientListPets(t *testing.T) {
+	want := Pets{{ID: 1, Name: "doggie"}}
+	svc := newTestService(t, &petServer{pets: want})
+
+	resp, err := svc.PetsService.ListPets().Do(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got Pets
+	if err := json.Unmarshal(resp.Body, &got); err != nil {
+		t.Fatal(err)
+	}
+	if len(got) != 1 || got[0].ID != 1 || got[0].Name != "doggie" {
+		t.Errorf("Body = %s, want the pets", resp.Body)
+	}
+}
+
+func TestClientResponseError(t *testing.T) {
+	svc := newTestService(t, new(petServer))
+
+	resp, err := svc.PetsService.ShowPetByID("42").Do(context.Background())
+	if resp != nil {
+		t.Errorf("response = %+v, want nil", resp)
+	}
+	var respErr *ResponseError
+	if !errors.As(err, &respErr) {
+		t.Fatalf("error = %v, want *ResponseError", err)
+	}
+	if respErr.HTTPStatusCode != http.StatusNotFound {
+		t.Errorf("HTTPStatusCode = %d, want %d", respErr.HTTPStatusCode, http.StatusNotFound)
+	}
+	if got, want := err.Error(), "404 Not Found"; got != want {
+		t.Errorf("Error() = %q, want %q", got, want)
+	}
+	if ct := respErr.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var body Error
+	if err := json.Unmarshal(respErr.Body, &body); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Error{Code: 404, Message: "pet 42 is not found"}); body != want {
+		t.Errorf("Body = %+v, want %+v", body, want)
+	}
+}
@@ -0,0 +1,34 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package fake
+
+import (
+	"context"
+	"testing"
+
+	"example.com/petstore"
+)
+
+func TestFakeRecordsBodyCopy(t *testing.T) {
+	f := New()
+
+	pet := &petstore.Pet{ID: 1, Name: "doggie", Tags: []string{"dog"}}
+	if _, err := f.Service.PetsService.CreatePets(pet).Do(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	pet.Name = "kitty"
+	pet.Tags[0] = "cat"
+
+	calls := f.PetsService.CreatePetsCalls()
+	if len(calls) != 1 {
+		t.Fatalf("calls = %d, want 1", len(calls))
+	}
+	got, ok := calls[0].Body.(*petstore.Pet)
+	if !ok {
+		t.Fatalf("Body = %T, want *petstore.Pet", calls[0].Body)
+	}
+	if got == pet || got.Name != "doggie" || got.Tags[0] != "dog" {
+		t.Errorf("Body = %+v, want the copy of the sent pet", got)
+	}
+}
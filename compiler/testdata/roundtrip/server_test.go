@@ -0,0 +1,138 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package petstore
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+// petServer is the ServerInterface which serves the fixed pets.
+type petServer struct {
+	pets Pets
+}
+
+var _ ServerInterface = (*petServer)(nil)
+
+func (s *petServer) ListPets(ctx context.Context, params *ListPetsParams) (*HandlerResponse, error) {
+	return ListPets200Response(s.pets), nil
+}
+
+func (s *petServer) CreatePets(ctx context.Context, body *Pet) (*HandlerResponse, error) {
+	s.pets = append(s.pets, body)
+	return new(HandlerResponse), nil // the documented 201 Created
+}
+
+func (s *petServer) ShowPetByID(ctx context.Context, petID string) (*HandlerResponse, error) {
+	return ShowPetByIDDefaultResponse(http.StatusNotFound, Error{Code: 404, Message: "pet " + petID + " is not found"}), nil
+}
+
+func (s *petServer) Inventory(ctx context.Context) (*HandlerResponse, error) {
+	return Inventory200Response(map[string]interface{}{"available": 1}), nil
+}
+
+func TestServerListPets(t *testing.T) {
+	want := Pets{{ID: 1, Name: "doggie"}, {ID: 2, Name: "kitty", Tag: "cat"}}
+	srv := httptest.NewServer(Handler(&petServer{pets: want}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/pets")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("status code = %d, want %d", resp.StatusCode, http.StatusOK)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != "application/json" {
+		t.Errorf("Content-Type = %q, want application/json", ct)
+	}
+	var got Pets
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(got, want) {
+		gotJSON, _ := json.Marshal(got)
+		wantJSON, _ := json.Marshal(want)
+		t.Errorf("body = %s, want %s", gotJSON, wantJSON)
+	}
+	if err := got.Validate(); err != nil {
+		t.Errorf("Validate() = %v", err)
+	}
+}
+
+func TestServerDefaultResponse(t *testing.T) {
+	srv := httptest.NewServer(Handler(&petServer{}))
+	defer srv.Close()
+
+	resp, err := http.Get(srv.URL + "/pets/42")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusNotFound {
+		t.Fatalf("status code = %d, want %d", resp.StatusCode, http.StatusNotFound)
+	}
+	var got Error
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if want := (Error{Code: 404, Message: "pet 42 is not found"}); got != want {
+		t.Errorf("body = %+v, want %+v", got, want)
+	}
+}
+
+func TestServerDefaultStatusCode(t *testing.T) {
+	s := new(petServer)
+	srv := httptest.NewServer(Handler(s))
+	defer srv.Close()
+
+	resp, err := http.Post(srv.URL+"/pets", "application/json", strings.NewReader(`{"id":3,"name":"bird"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+
+	if resp.StatusCode != http.StatusCreated {
+		t.Fatalf("status code = %d, want %d", resp.StatusCode, http.StatusCreated)
+	}
+	if len(s.pets) != 1 || s.pets[0].Name != "bird" {
+		t.Errorf("pets = %v, want the created pet", s.pets)
+	}
+}
+
+func TestServerValidationMiddleware(t *testing.T) {
+	mw, err := ValidationMiddleware()
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := httptest.NewServer(mw(Handler(new(petServer))))
+	defer srv.Close()
+
+	tests := map[string]struct {
+		body   string
+		status int
+	}{
+		"Valid":       {body: `{"id":1,"name":"doggie","owner":{"email":"a@example.com","id":"0b5b2f1c-9d4e-4c4f-8c36-b1f0f7b6a2a1"}}`, status: http.StatusCreated},
+		"MissingName": {body: `{"id":1}`, status: http.StatusBadRequest},
+		"InvalidUUID": {body: `{"id":1,"name":"doggie","owner":{"email":"a@example.com","id":"42"}}`, status: http.StatusBadRequest},
+	}
+	for name, tt := range tests {
+		resp, err := http.Post(srv.URL+"/pets", "application/json", strings.NewReader(tt.body))
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if resp.StatusCode != tt.status {
+			t.Errorf("%s: status code = %d, want %d", name, resp.StatusCode, tt.status)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	pathpkg "path"
+	"runtime"
+	"strings"
+
+	"github.com/zchee/go-openapi-tools/diag"
+	"github.com/zchee/go-openapi-tools/ir"
+)
+
+// WithTypeCheck type-checks the generated packages by go/types, and runs the go vet analyzers such as printf on them
+// before writing the files. It fails the generation with the diag.List of the type errors and the vet findings.
+//
+// The check is isolated from the module of the output directory. The standard packages are type-checked from the
+// GOROOT sources, and the other imported packages such as the mapped types are not type-checked, the references to
+// those are assumed to be valid and reported as the informational diagnostics.
+func WithTypeCheck() Option {
+	return func(g *Generator) {
+		g.typeCheck = true
+	}
+}
+
+// modelPointer returns the JSON pointer of the component schema.
+func modelPointer(name string) string {
+	return diag.Pointer("components", "schemas", name)
+}
+
+// operationPointer returns the JSON pointer of the operation.
+func operationPointer(op *ir.Operation) string {
+	return diag.Pointer("paths", op.Path, strings.ToLower(op.Method))
+}
+
+// declareSource records the spec location which the Go declaration is generated from, which is reported with the
+// diagnostics of the declaration.
+//
+// decl is the type or function name, the receiver type and the method name joined by dot such as "T.Method", or the
+// generated file name.
+func (g *Generator) declareSource(decl, pointer string) {
+	if g.sources == nil {
+		g.sources = make(map[string]string)
+	}
+	g.sources[decl] = pointer
+}
+
+// declSource returns the spec location of the declaration recorded by declareSource. The method falls back to the
+// location of the receiver type.
+func (g *Generator) declSource(decl string) string {
+	if pointer, ok := g.sources[decl]; ok {
+		return pointer
+	}
+	if idx := strings.IndexByte(decl, '.'); idx >= 0 && !strings.HasSuffix(decl, ".go") {
+		return g.sources[decl[:idx]]
+	}
+
+	return ""
+}
+
+// errSkippedImport is the import error of the non-standard packages, which are not type-checked.
+var errSkippedImport = errors.New("not type-checked")
+
+// checkImporter imports the generated packages and the standard packages for the type check.
+type checkImporter struct {
+	std     types.ImporterFrom
+	pkgs    map[string]*types.Package // generated packages keyed by the import path
+	skipped map[string]bool           // import paths which are not type-checked
+}
+
+// Import implements types.Importer.
+func (imp *checkImporter) Import(path string) (*types.Package, error) {
+	if pkg, ok := imp.pkgs[path]; ok {
+		return pkg, nil
+	}
+	if !isStdImportPath(path) {
+		// go/types treats the package as fake, and does not report the errors of the references to it
+		imp.skipped[path] = true
+		return nil, errSkippedImport
+	}
+
+	return imp.std.ImportFrom(path, "", 0)
+}
+
+// checkTypes type-checks the generated packages, the package of the output directory and the fake subpackage.
+func (g *Generator) checkTypes() error {
+	pkgFiles := make(map[string][]string) // directory to the Go file names
+	for _, name := range SortedMapKeys(g.files) {
+		if strings.HasSuffix(name, ".go") {
+			dir := pathpkg.Dir(name)
+			pkgFiles[dir] = append(pkgFiles[dir], name)
+		}
+	}
+
+	importPath := g.fake
+	if importPath == "" {
+		importPath = g.pkgName
+	}
+
+	fset := token.NewFileSet()
+	imp := &checkImporter{
+		std:     importer.ForCompiler(fset, "source", nil).(types.ImporterFrom),
+		pkgs:    make(map[string]*types.Package),
+		skipped: make(map[string]bool),
+	}
+	sizes := types.SizesFor("gc", runtime.GOARCH)
+	facts := newVetFacts()
+
+	var errs diag.List
+	// checks the package of the output directory first, the fake package imports it
+	for _, dir := range SortedMapKeys(pkgFiles) {
+		path := importPath
+		if dir != "." {
+			path = pathpkg.Join(importPath, dir)
+		}
+
+		var files []*ast.File
+		for _, name := range pkgFiles[dir] {
+			f, err := parser.ParseFile(fset, name, g.files[name], 0)
+			if err != nil {
+				return fmt.Errorf("failed to parse %s: %w", name, err)
+			}
+			files = append(files, f)
+		}
+
+		typeErrs := len(errs)
+		conf := &types.Config{
+			Importer: imp,
+			Sizes:    sizes,
+			Error: func(err error) {
+				terr, ok := err.(types.Error)
+				if !ok {
+					errs = append(errs, diag.Errorf("", "%v", err))
+					return
+				}
+				if strings.HasSuffix(terr.Msg, "("+errSkippedImport.Error()+")") {
+					return
+				}
+				// the position of the generated code is the part of the message, the diagnostic is located in the spec
+				pointer := g.declSource(enclosingDecl(files, terr.Pos))
+				errs = append(errs, diag.Errorf(pointer, "%s: %s", terr.Fset.Position(terr.Pos), terr.Msg))
+			},
+		}
+		info := &types.Info{
+			Types:      make(map[ast.Expr]types.TypeAndValue),
+			Instances:  make(map[*ast.Ident]types.Instance),
+			Defs:       make(map[*ast.Ident]types.Object),
+			Uses:       make(map[*ast.Ident]types.Object),
+			Implicits:  make(map[ast.Node]types.Object),
+			Selections: make(map[*ast.SelectorExpr]*types.Selection),
+			Scopes:     make(map[ast.Node]*types.Scope),
+		}
+		pkg, _ := conf.Check(path, fset, files, info) // the errors are collected by conf.Error
+		imp.pkgs[path] = pkg
+		if len(errs) > typeErrs {
+			continue // the analyzers require the well-typed package
+		}
+
+		vetErrs, err := g.vet(fset, files, pkg, info, sizes, facts)
+		if err != nil {
+			return err
+		}
+		errs = append(errs, vetErrs...)
+	}
+	for _, path := range SortedMapKeys(imp.skipped) {
+		g.infof("", "package %s is not type-checked, the references to it are assumed to be valid", path)
+	}
+	g.logger.Debug("type-checked generated code", "packages", len(pkgFiles), "errors", len(errs))
+	if len(errs) == 0 {
+		return nil
+	}
+
+	return errs
+}
+
+// enclosingDecl returns the name of the top level declaration which encloses pos, in the form of declareSource.
+func enclosingDecl(files []*ast.File, pos token.Pos) string {
+	for _, f := range files {
+		if pos < f.Pos() || pos > f.End() {
+			continue
+		}
+		for _, decl := range f.Decls {
+			if pos < decl.Pos() || pos > decl.End() {
+				continue
+			}
+
+			switch decl := decl.(type) {
+			case *ast.FuncDecl:
+				if decl.Recv == nil || len(decl.Recv.List) == 0 {
+					return decl.Name.Name
+				}
+				recv := decl.Recv.List[0].Type
+				if star, ok := recv.(*ast.StarExpr); ok {
+					recv = star.X
+				}
+				if id, ok := recv.(*ast.Ident); ok {
+					return id.Name + "." + decl.Name.Name
+				}
+
+			case *ast.GenDecl:
+				for _, spec := range decl.Specs {
+					if pos < spec.Pos() || pos > spec.End() {
+						continue
+					}
+					switch spec := spec.(type) {
+					case *ast.TypeSpec:
+						return spec.Name.Name
+					case *ast.ValueSpec:
+						return spec.Names[0].Name
+					}
+				}
+			}
+			return ""
+		}
+	}
+
+	return ""
+}
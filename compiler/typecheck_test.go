@@ -0,0 +1,151 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"errors"
+	"strings"
+	"testing"
+
+	"github.com/zchee/go-openapi-tools/diag"
+)
+
+func TestCheckTypes(t *testing.T) {
+	tests := map[string]struct {
+		src     string
+		wantErr string
+	}{
+		"Valid": {
+			src: "package api\n\nimport \"fmt\"\n\nfunc F(x int32) string { return fmt.Sprint(x) }\n",
+		},
+		"TypeError": {
+			src:     "package api\n\nfunc F(x int32) string { return x }\n",
+			wantErr: "cannot use x",
+		},
+		"Printf": {
+			src:     "package api\n\nimport \"fmt\"\n\nfunc F(x int32) string { return fmt.Sprintf(\"%s\", x) }\n",
+			wantErr: "printf: fmt.Sprintf format %s has arg x of wrong type int32",
+		},
+		"Unreachable": {
+			src:     "package api\n\nfunc F() int {\n\treturn 1\n\tpanic(\"unreachable\")\n}\n",
+			wantErr: "unreachable: unreachable code",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			g := &Generator{
+				pkgName: "api",
+				logger:  discardLogger,
+				files:   map[string][]byte{"api.go": []byte(tt.src)},
+			}
+			err := g.checkTypes()
+			if tt.wantErr == "" {
+				if err != nil {
+					t.Fatal(err)
+				}
+				return
+			}
+			var diags diag.List
+			if !errors.As(err, &diags) || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Fatalf("checkTypes() error = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestCheckTypesSkippedImport(t *testing.T) {
+	g := &Generator{
+		pkgName: "api",
+		logger:  discardLogger,
+		files: map[string][]byte{
+			"api.go": []byte("package api\n\nimport \"example.com/money\"\n\nvar Price money.Amount\n"),
+		},
+	}
+	if err := g.checkTypes(); err != nil {
+		t.Fatal(err)
+	}
+	if len(g.diags) != 1 || g.diags[0].Severity != diag.Info || !strings.Contains(g.diags[0].Msg, "example.com/money is not type-checked") {
+		t.Fatalf("diagnostics = %v, want the informational diagnostic of the skipped import", g.diags)
+	}
+}
+
+func TestTypeCheckPathParam(t *testing.T) {
+	const spec = `openapi: 3.0.3
+info:
+  title: Items
+  version: 1.0.0
+paths:
+  /items/{id}:
+    get:
+      operationId: getItem
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+            format: int32
+      responses:
+        '204':
+          description: No content
+`
+	g, err := NewFromReader(strings.NewReader(spec), WithPackageName("items"), WithTypeCheck())
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := g.GenerateFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(string(files["api_default.go"]), `+ "/items/" + fmt.Sprint(c.id)`) {
+		t.Fatalf("the path parameter is not formatted by fmt.Sprint:\n%s", files["api_default.go"])
+	}
+}
+
+func TestServerOptionalBody(t *testing.T) {
+	const spec = `openapi: 3.0.3
+info:
+  title: Items
+  version: 1.0.0
+paths:
+  /items:
+    put:
+      operationId: putItem
+      requestBody:
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Item'
+      responses:
+        '204':
+          description: No content
+components:
+  schemas:
+    Item:
+      type: object
+      properties:
+        name:
+          type: string
+`
+	g, err := NewFromReader(strings.NewReader(spec), WithPackageName("items"), WithServer(), WithTypeCheck())
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := g.GenerateFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+	src := string(files["server.go"])
+	for _, want := range []string{
+		"var body *Item\n\tif r.ContentLength != 0 && r.Body != http.NoBody {",
+		"a.writeResponse(w, r, 204, resp, err)",
+	} {
+		if !strings.Contains(src, want) {
+			t.Errorf("server.go does not contain %q:\n%s", want, src)
+		}
+	}
+}
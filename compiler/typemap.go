@@ -0,0 +1,136 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"fmt"
+	pathpkg "path"
+	"regexp"
+	"sort"
+	"strings"
+
+	"github.com/zchee/go-openapi-tools/ir"
+)
+
+// mappedType represents the Go type which the schema type is mapped to.
+type mappedType struct {
+	typ string          // qualified Go type, such as "uuid.UUID"
+	pkg externalPackage // imported package, empty if typ is predeclared
+}
+
+// majorVersionRe matches the major version suffix of the module path.
+var majorVersionRe = regexp.MustCompile(`^v[0-9]+$`)
+
+// parseMappedType parses the Go type such as "time.Time", "github.com/google/uuid.UUID" or "[]byte".
+func parseMappedType(s string) (*mappedType, error) {
+	prefix := s[:len(s)-len(strings.TrimLeft(s, "*[]"))]
+	name := s[len(prefix):]
+
+	idx := strings.LastIndexByte(name, '.')
+	if idx < 0 {
+		if name == "" || strings.Contains(name, "/") {
+			return nil, fmt.Errorf("invalid Go type %q", s)
+		}
+		return &mappedType{typ: s}, nil // predeclared type
+	}
+
+	importPath, typeName := name[:idx], name[idx+1:]
+	if importPath == "" || typeName == "" || strings.ContainsAny(typeName, "/") {
+		return nil, fmt.Errorf("invalid Go type %q", s)
+	}
+
+	pkgName := packageName(importPath)
+	mt := &mappedType{
+		typ: prefix + pkgName + "." + typeName,
+		pkg: externalPackage{pkg: importPath},
+	}
+	if pkgName != pathpkg.Base(importPath) {
+		mt.pkg.alias = pkgName
+	}
+
+	return mt, nil
+}
+
+// WithTypeMappings maps the schema types of the model fields to the Go types.
+//
+// The key is the schema type, optionally followed by the format such as "string:uuid", and the value is the Go type
+// qualified by the import path such as "github.com/google/uuid.UUID". The type with format takes precedence.
+// The constraints of the mapped field are not validated by the generated Validate method.
+func WithTypeMappings(mappings map[string]string) Option {
+	return func(g *Generator) {
+		for key, typ := range mappings {
+			g.typeMappings[key] = typ
+		}
+	}
+}
+
+// parseTypeMappings parses the type mappings which given by WithTypeMappings.
+func (g *Generator) parseTypeMappings() error {
+	g.mappedTypes = make(map[string]*mappedType, len(g.typeMappings))
+
+	keys := make([]string, 0, len(g.typeMappings))
+	for key := range g.typeMappings {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	for _, key := range keys {
+		mt, err := parseMappedType(g.typeMappings[key])
+		if err != nil {
+			return fmt.Errorf("failed to parse type mapping of %s: %w", key, err)
+		}
+		g.mappedTypes[key] = mt
+	}
+
+	return nil
+}
+
+// fieldType returns the Go type of the model field which has the primitive or object schema.
+//
+// The imported package of the mapped type is recorded to be imported by importedPackages.
+func (g *Generator) fieldType(t *ir.Type) (string, bool) {
+	if mt, ok := g.lookupMappedType(t); ok {
+		if mt.pkg.pkg != "" {
+			g.fileImports[mt.pkg.pkg] = mt.pkg
+		}
+		return mt.typ, true
+	}
+
+	return primitiveType(t)
+}
+
+func (g *Generator) lookupMappedType(t *ir.Type) (*mappedType, bool) {
+	name := schemaTypeName(t)
+	if t.Format != "" {
+		if mt, ok := g.mappedTypes[name+":"+t.Format]; ok {
+			return mt, true
+		}
+	}
+	mt, ok := g.mappedTypes[name]
+
+	return mt, ok
+}
+
+// isMappedType reports whether the Go type typ, or its element type, is mapped to the imported type by
+// WithTypeMappings.
+func (g *Generator) isMappedType(typ string) bool {
+	typ = strings.TrimPrefix(typ, "[]")
+	for _, mt := range g.mappedTypes {
+		if mt.typ == typ && mt.pkg.pkg != "" {
+			return true
+		}
+	}
+
+	return false
+}
+
+// importedPackages returns the packages recorded by fieldType, sorted by the import path.
+func (g *Generator) importedPackages() []externalPackage {
+	extPkgs := make([]externalPackage, 0, len(g.fileImports))
+	for _, path := range SortedMapKeys(g.fileImports) {
+		extPkgs = append(extPkgs, g.fileImports[path])
+	}
+
+	return extPkgs
+}
@@ -0,0 +1,398 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/zchee/go-openapi-tools/diag"
+	"github.com/zchee/go-openapi-tools/ir"
+)
+
+// isModelSchema reports whether the type is generated as the model struct which has Validate method.
+func isModelSchema(t *ir.Type) bool {
+	return t != nil && t.Kind == ir.Struct && len(t.Fields) > 0 && t.Additional == nil
+}
+
+// patternVar returns the package level variable name of the compiled regexp pattern.
+//
+// The variable declaration is rendered in the file which uses the pattern at first, see takePatterns.
+func (g *Generator) patternVar(pattern string) string {
+	if g.patterns == nil {
+		g.patterns = make(map[string]string)
+	}
+	if name, ok := g.patterns[pattern]; ok {
+		return name
+	}
+
+	name := "pattern" + strconv.Itoa(len(g.patterns))
+	g.patterns[pattern] = name
+	g.pendingPatterns = append(g.pendingPatterns, pattern)
+
+	return name
+}
+
+// supportedPattern reports whether the regexp pattern of the schema located at pointer is compiled by the Go regexp
+// package. The ECMA-262 patterns such as lookahead and backreferences are not supported, it warns once per schema.
+func (g *Generator) supportedPattern(pointer, pattern string) bool {
+	_, err := regexp.Compile(pattern)
+	if err == nil {
+		return true
+	}
+	if key := [2]string{pointer, pattern}; !g.badPatterns[key] {
+		if g.badPatterns == nil {
+			g.badPatterns = make(map[[2]string]bool)
+		}
+		g.badPatterns[key] = true
+		g.warnf(pointer, "pattern %q is not supported by Go regexp, the pattern is not validated: %v", pattern, err)
+	}
+
+	return false
+}
+
+// schemaPointer returns the JSON pointer of the schema t located at pointer, or the pointer of the component schema
+// which t references.
+func schemaPointer(pointer string, t *ir.Type) string {
+	for seen := 0; t != nil && t.Kind == ir.Ref && seen <= 64; seen++ {
+		pointer = modelPointer(t.Name)
+		t = t.Elem
+	}
+
+	return pointer
+}
+
+// takePatterns returns the compiled regexp pattern variables which are used in the current file at first, and which
+// must be declared in the file.
+func (g *Generator) takePatterns() []*PatternData {
+	patterns := make([]*PatternData, len(g.pendingPatterns))
+	for i, pattern := range g.pendingPatterns {
+		patterns[i] = &PatternData{Name: g.patterns[pattern], Pattern: pattern}
+	}
+	g.pendingPatterns = nil
+
+	return patterns
+}
+
+// formatFloat formats f as Go literal.
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'g', -1, 64)
+}
+
+// isNumericType reports whether the typ is Go numeric type.
+func isNumericType(typ string) bool {
+	switch typ {
+	case "int", "int32", "int64", "float32", "float64":
+		return true
+	default:
+		return false
+	}
+}
+
+// isComparableType reports whether the typ is Go comparable basic type.
+func isComparableType(typ string) bool {
+	return typ == "string" || typ == "bool" || isNumericType(typ)
+}
+
+// hasConstraints reports whether schema has any constraint which checked by checks.
+func hasConstraints(typ string, schema *ir.Type) bool {
+	if strings.HasPrefix(typ, "*") {
+		return true // nested model
+	}
+	if schema == nil {
+		return strings.HasPrefix(typ, "[]*")
+	}
+
+	switch {
+	case typ == "string":
+		return schema.MinLength > 0 || schema.MaxLength != nil || schema.Pattern != "" || len(schema.Values) > 0 || formatChecks[schema.Format] != ""
+
+	case isNumericType(typ):
+		return schema.Min != nil || schema.Max != nil || schema.MultipleOf != nil || len(schema.Values) > 0
+
+	case strings.HasPrefix(typ, "[]"):
+		if schema.MinItems > 0 || schema.MaxItems != nil || (schema.UniqueItems && isComparableType(typ[2:])) {
+			return true
+		}
+		return hasConstraints(typ[2:], schema.Elem.Resolve())
+	}
+
+	return false
+}
+
+// checks returns the statements which check the value of expr against the constraints of schema.
+//
+// typ is the Go type of expr, field is the Go expression of the field name which used in ValidationError, and pointer
+// is the JSON pointer of schema which the warnings are located at.
+func (g *Generator) checks(expr, typ, field, pointer string, schema *ir.Type) []*CheckData {
+	if strings.HasPrefix(typ, "*") {
+		return []*CheckData{{Kind: CheckModel, Expr: expr, Field: field}}
+	}
+	if schema == nil {
+		if strings.HasPrefix(typ, "[]*") {
+			return []*CheckData{{
+				Kind:   CheckEach,
+				Expr:   expr,
+				Field:  field,
+				Checks: g.checks("v", typ[2:], elemField(field), pointer, nil),
+			}}
+		}
+		return nil
+	}
+
+	var checks []*CheckData
+	cond := func(cond, reason string) {
+		checks = append(checks, &CheckData{Kind: CheckCond, Expr: expr, Field: field, Cond: cond, Reason: reason})
+	}
+
+	switch {
+	case typ == "string":
+		if schema.MinLength > 0 {
+			cond(fmt.Sprintf("utf8.RuneCountInString(%s) < %d", expr, schema.MinLength), fmt.Sprintf("length must be at least %d", schema.MinLength))
+		}
+		if schema.MaxLength != nil {
+			cond(fmt.Sprintf("utf8.RuneCountInString(%s) > %d", expr, *schema.MaxLength), fmt.Sprintf("length must be at most %d", *schema.MaxLength))
+		}
+		if schema.Pattern != "" && g.supportedPattern(pointer, schema.Pattern) {
+			cond(fmt.Sprintf("!%s.MatchString(%s)", g.patternVar(schema.Pattern), expr), fmt.Sprintf("must match pattern %q", schema.Pattern))
+		}
+		if check := formatChecks[schema.Format]; check != "" {
+			if schema.Format == "uuid" {
+				check = fmt.Sprintf(check, g.patternVar(uuidPattern), "%[1]s")
+			}
+			cond(fmt.Sprintf(check, expr), fmt.Sprintf("must be a valid %s", schema.Format))
+		}
+		if check := enumCheck(expr, typ, field, schema.Values); check != nil {
+			checks = append(checks, check)
+		}
+
+	case isNumericType(typ):
+		if schema.Min != nil {
+			op, reason := "<", "must be at least %s"
+			if schema.ExclusiveMin {
+				op, reason = "<=", "must be greater than %s"
+			}
+			cond(fmt.Sprintf("float64(%s) %s %s", expr, op, formatFloat(*schema.Min)), fmt.Sprintf(reason, formatFloat(*schema.Min)))
+		}
+		if schema.Max != nil {
+			op, reason := ">", "must be at most %s"
+			if schema.ExclusiveMax {
+				op, reason = ">=", "must be less than %s"
+			}
+			cond(fmt.Sprintf("float64(%s) %s %s", expr, op, formatFloat(*schema.Max)), fmt.Sprintf(reason, formatFloat(*schema.Max)))
+		}
+		if schema.MultipleOf != nil && *schema.MultipleOf != 0 {
+			cond(fmt.Sprintf("math.Mod(float64(%s), %s) != 0", expr, formatFloat(*schema.MultipleOf)), fmt.Sprintf("must be a multiple of %s", formatFloat(*schema.MultipleOf)))
+		}
+		if check := enumCheck(expr, typ, field, schema.Values); check != nil {
+			checks = append(checks, check)
+		}
+
+	case strings.HasPrefix(typ, "[]"):
+		elemType := typ[2:]
+		if schema.MinItems > 0 {
+			cond(fmt.Sprintf("len(%s) < %d", expr, schema.MinItems), fmt.Sprintf("must have at least %d items", schema.MinItems))
+		}
+		if schema.MaxItems != nil {
+			cond(fmt.Sprintf("len(%s) > %d", expr, *schema.MaxItems), fmt.Sprintf("must have at most %d items", *schema.MaxItems))
+		}
+		if schema.UniqueItems && isComparableType(elemType) {
+			checks = append(checks, &CheckData{
+				Kind:   CheckUnique,
+				Expr:   expr,
+				Field:  field,
+				Type:   elemType,
+				Reason: "must not have duplicate items",
+			})
+		}
+		if elem := schema.Elem.Resolve(); hasConstraints(elemType, elem) {
+			elemPointer := schemaPointer(pointer+"/items", schema.Elem)
+			if elemChecks := g.checks("v", elemType, elemField(field), elemPointer, elem); len(elemChecks) > 0 {
+				checks = append(checks, &CheckData{
+					Kind:   CheckEach,
+					Expr:   expr,
+					Field:  field,
+					Checks: elemChecks,
+				})
+			}
+		}
+	}
+
+	return checks
+}
+
+// elemField returns the Go expression of the field name of the element i of field.
+func elemField(field string) string {
+	if field == `""` {
+		return `"/" + strconv.Itoa(i)`
+	}
+
+	return field + ` + "/" + strconv.Itoa(i)`
+}
+
+// uuidPattern is the regexp pattern of the uuid format.
+const uuidPattern = `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
+
+// formatChecks is the map of the string format to the format of condition expression which reports the value is invalid.
+var formatChecks = map[string]string{
+	"date-time": "_, err := time.Parse(time.RFC3339, %[1]s); err != nil",
+	"date":      "_, err := time.Parse(\"2006-01-02\", %[1]s); err != nil",
+	"email":     "_, err := mail.ParseAddress(%[1]s); err != nil",
+	"uuid":      "!%[1]s.MatchString(%[2]s)",
+	"ipv4":      "ip := net.ParseIP(%[1]s); ip == nil || ip.To4() == nil",
+	"ipv6":      "ip := net.ParseIP(%[1]s); ip == nil || !strings.Contains(%[1]s, \":\")",
+	"uri":       "u, err := url.Parse(%[1]s); err != nil || !u.IsAbs()",
+	"hostname":  "len(%[1]s) > 253 || strings.ContainsAny(%[1]s, \" /:@\")",
+}
+
+// enumCheck returns the statement which checks the value of expr is one of enum, or nil if no enum of typ.
+func enumCheck(expr, typ, field string, enum []interface{}) *CheckData {
+	if len(enum) == 0 {
+		return nil
+	}
+
+	values := make([]string, 0, len(enum))
+	for _, e := range enum {
+		switch v := e.(type) {
+		case string:
+			if typ == "string" {
+				values = append(values, strconv.Quote(v))
+			}
+		case float64:
+			if isNumericType(typ) {
+				values = append(values, formatFloat(v))
+			}
+		}
+	}
+	if len(values) == 0 {
+		return nil
+	}
+
+	return &CheckData{
+		Kind:   CheckEnum,
+		Expr:   expr,
+		Field:  field,
+		Values: values,
+		Reason: "must be one of " + strings.Join(values, ", "),
+	}
+}
+
+// callChecks returns the statements of Validate method of the methType Call.
+//
+// pointer is the JSON pointer of the operation, and bodyType is the model name of the request body which has Validate
+// method, if any.
+func (g *Generator) callChecks(pointer, methType string, pathParams, queryParams []*ir.Param, bodyType string, bodyRequired bool) []*CheckData {
+	var checks []*CheckData
+
+	for _, param := range pathParams {
+		typ, ok := paramType(param)
+		if !ok {
+			continue
+		}
+		paramName := g.callParam(methType, param)
+		checks = append(checks, g.checks("c."+paramName, typ, strconv.Quote(param.Name), schemaPointer(pointer, param.Type), param.Type.Resolve())...)
+	}
+
+	for _, param := range queryParams {
+		typ, ok := paramType(param)
+		if !ok {
+			continue
+		}
+		paramName := g.callParam(methType, param)
+		field := strconv.Quote(param.Name)
+
+		if param.Required {
+			checks = append(checks, &CheckData{
+				Kind:   CheckCond,
+				Field:  field,
+				Cond:   fmt.Sprintf("_, ok := c.params[%q]; !ok", param.Name),
+				Reason: "required parameter is missing",
+			})
+		}
+		if schema := param.Type.Resolve(); hasConstraints(typ, schema) {
+			if guarded := g.checks("c."+paramName, typ, field, schemaPointer(pointer, param.Type), schema); len(guarded) > 0 {
+				checks = append(checks, &CheckData{
+					Kind:   CheckGuard,
+					Cond:   fmt.Sprintf("_, ok := c.params[%q]; ok", param.Name),
+					Checks: guarded,
+				})
+			}
+		}
+	}
+
+	if bodyRequired {
+		checks = append(checks, &CheckData{
+			Kind:   CheckCond,
+			Field:  `"body"`,
+			Cond:   "c.body == nil",
+			Reason: "required request body is missing",
+		})
+	}
+	if bodyType != "" { // the missing body is not validated, it is reported by the required check
+		checks = append(checks, &CheckData{Kind: CheckModel, Expr: "c.body", Field: `""`})
+	}
+
+	return checks
+}
+
+// modelChecks returns the statements of Validate method of the model.
+//
+// propertyTypes is the map of property name to the Go type of the model field.
+func (g *Generator) modelChecks(modelName string, model *ir.Type, propertyTypes map[string]string) []*CheckData {
+	reciever := receiverName(g.modelType(modelName))
+
+	var checks []*CheckData
+	for _, property := range model.Fields {
+		name := property.Name
+		typ := propertyTypes[name]
+		schema := property.Type.Resolve()
+		if typ == "" || schema == nil {
+			continue
+		}
+		expr := reciever + "." + g.modelField(modelName, name, g.depunct(name, true))
+		field := strconv.Quote("/" + name)
+		pointer := schemaPointer(diag.Pointer("components", "schemas", modelName, "properties", name), property.Type)
+		required := property.Required
+
+		if required && (strings.HasPrefix(typ, "*") || strings.HasPrefix(typ, "[]") || strings.HasPrefix(typ, "map[") || typ == "interface{}") {
+			checks = append(checks, &CheckData{
+				Kind:   CheckCond,
+				Expr:   expr,
+				Field:  field,
+				Cond:   expr + " == nil",
+				Reason: "required property is missing",
+			})
+		}
+
+		if g.isMappedType(typ) || !hasConstraints(typ, schema) {
+			continue
+		}
+		if required || strings.HasPrefix(typ, "*") { // the model checks nil
+			checks = append(checks, g.checks(expr, typ, field, pointer, schema)...)
+			continue
+		}
+		guarded := g.checks(expr, typ, field, pointer, schema)
+		if len(guarded) == 0 {
+			continue
+		}
+
+		// skip the zero value of optional property, it omitted from JSON
+		zero := "0"
+		switch {
+		case typ == "string":
+			zero = `""`
+		case strings.HasPrefix(typ, "[]"):
+			zero = "nil"
+		}
+		checks = append(checks, &CheckData{
+			Kind:   CheckGuard,
+			Cond:   expr + " != " + zero,
+			Checks: guarded,
+		})
+	}
+
+	return checks
+}
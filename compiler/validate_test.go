@@ -0,0 +1,83 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/zchee/go-openapi-tools/diag"
+)
+
+func TestUnsupportedPattern(t *testing.T) {
+	const spec = `openapi: 3.0.3
+info:
+  title: Items
+  version: 1.0.0
+paths:
+  /items:
+    get:
+      operationId: listItems
+      parameters:
+        - name: q
+          in: query
+          schema:
+            type: string
+            pattern: '^(?=a)'
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Item'
+components:
+  schemas:
+    Item:
+      type: object
+      properties:
+        name:
+          type: string
+          pattern: '^(?=a)'
+        tags:
+          type: array
+          items:
+            type: string
+            pattern: '^[a-z]+$'
+`
+	g, err := NewFromReader(strings.NewReader(spec), WithPackageName("items"), WithTypeCheck())
+	if err != nil {
+		t.Fatal(err)
+	}
+	files, err := g.GenerateFiles()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	var src strings.Builder
+	for _, name := range SortedMapKeys(files) {
+		src.Write(files[name])
+	}
+	if strings.Contains(src.String(), "(?=a)") {
+		t.Errorf("the unsupported pattern is compiled:\n%s", src.String())
+	}
+	if !strings.Contains(src.String(), "regexp.MustCompile(\"^[a-z]+$\")") {
+		t.Errorf("the supported pattern is not compiled:\n%s", src.String())
+	}
+
+	var warnings []string
+	for _, d := range g.Diagnostics() {
+		if d.Severity == diag.Warning {
+			warnings = append(warnings, d.Pointer+": "+d.Msg)
+		}
+	}
+	want := []string{
+		`#/paths/~1items/get: pattern "^(?=a)" is not supported by Go regexp, the pattern is not validated: error parsing regexp: invalid or unsupported Perl syntax: ` + "`(?=`",
+		`#/components/schemas/Item/properties/name: pattern "^(?=a)" is not supported by Go regexp, the pattern is not validated: error parsing regexp: invalid or unsupported Perl syntax: ` + "`(?=`",
+	}
+	if !reflect.DeepEqual(warnings, want) {
+		t.Errorf("warnings = %q, want %q", warnings, want)
+	}
+}
@@ -0,0 +1,89 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/zchee/go-openapi-tools/internal/openapi31"
+)
+
+// swaggerVersion is the supported version of the Swagger schema.
+const swaggerVersion = "2.0"
+
+// openAPIVersionRe matches the OpenAPI 3.0 versions, the OpenAPI 3.1 versions are converted to 3.0 by openapi31.
+var openAPIVersionRe = regexp.MustCompile(`^3\.0\.\d+$`)
+
+// DetectSchemaType detects the schema type of the JSON or YAML schema data, one of (openapi, swagger).
+//
+// The schema type is detected from the version of the top-level "swagger" or "openapi" field, and it returns the error
+// if the version is not supported.
+func DetectSchemaType(data []byte) (string, error) {
+	st, err := detectSchemaType(data)
+	if err != nil {
+		return "", err
+	}
+
+	return st.String(), nil
+}
+
+// detectSchemaType detects the schema type from the version field of the schema data.
+func detectSchemaType(data []byte) (schemaType, error) {
+	st, _, err := schemaVersion(data)
+	return st, err
+}
+
+// IsOpenAPI31 reports whether the JSON or YAML schema data is the OpenAPI 3.1 schema, which is converted to
+// OpenAPI 3.0 when loaded.
+func IsOpenAPI31(data []byte) bool {
+	st, version, err := schemaVersion(data)
+	return err == nil && st == openAPISchema && openapi31.IsVersion(version)
+}
+
+// schemaVersion detects the schema type and its version from the version field of the schema data.
+func schemaVersion(data []byte) (schemaType, string, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return unknownSchema, "", fmt.Errorf("failed to parse schema: %w", err)
+	}
+	if len(root.Content) == 0 || root.Content[0].Kind != yaml.MappingNode {
+		return unknownSchema, "", errors.New("schema is not an object")
+	}
+
+	// the version fields of the top-level object, the other fields are not decoded
+	versions := make(map[string]string)
+	top := root.Content[0]
+	for i := 0; i+1 < len(top.Content); i += 2 {
+		key, val := top.Content[i], top.Content[i+1]
+		if (key.Value == SchemaNameSwagger || key.Value == SchemaNameOpenAPI) && val.Kind == yaml.ScalarNode {
+			versions[key.Value] = val.Value
+		}
+	}
+	swagger, isSwagger := versions[SchemaNameSwagger]
+	openAPI, isOpenAPI := versions[SchemaNameOpenAPI]
+
+	switch {
+	case isSwagger && isOpenAPI:
+		return unknownSchema, "", fmt.Errorf("schema has both %q and %q version fields", SchemaNameSwagger, SchemaNameOpenAPI)
+
+	case isSwagger:
+		if swagger != swaggerVersion {
+			return unknownSchema, "", fmt.Errorf("unsupported Swagger version %q, supports %s", swagger, swaggerVersion)
+		}
+		return swaggerSchema, swagger, nil
+
+	case isOpenAPI:
+		if !openAPIVersionRe.MatchString(openAPI) && !openapi31.IsVersion(openAPI) {
+			return unknownSchema, "", fmt.Errorf("unsupported OpenAPI version %q, supports 3.0.x and 3.1.x", openAPI)
+		}
+		return openAPISchema, openAPI, nil
+
+	default:
+		return unknownSchema, "", fmt.Errorf("no %q or %q version field at the top level of the schema", SchemaNameSwagger, SchemaNameOpenAPI)
+	}
+}
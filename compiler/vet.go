@@ -0,0 +1,164 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"fmt"
+	"go/ast"
+	"go/token"
+	"go/types"
+	"io/fs"
+	"reflect"
+
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/assign"
+	"golang.org/x/tools/go/analysis/passes/atomic"
+	"golang.org/x/tools/go/analysis/passes/bools"
+	"golang.org/x/tools/go/analysis/passes/copylock"
+	"golang.org/x/tools/go/analysis/passes/errorsas"
+	"golang.org/x/tools/go/analysis/passes/httpresponse"
+	"golang.org/x/tools/go/analysis/passes/loopclosure"
+	"golang.org/x/tools/go/analysis/passes/lostcancel"
+	"golang.org/x/tools/go/analysis/passes/nilfunc"
+	"golang.org/x/tools/go/analysis/passes/printf"
+	"golang.org/x/tools/go/analysis/passes/shift"
+	"golang.org/x/tools/go/analysis/passes/stdmethods"
+	"golang.org/x/tools/go/analysis/passes/stringintconv"
+	"golang.org/x/tools/go/analysis/passes/structtag"
+	"golang.org/x/tools/go/analysis/passes/unmarshal"
+	"golang.org/x/tools/go/analysis/passes/unreachable"
+	"golang.org/x/tools/go/analysis/passes/unusedresult"
+
+	"github.com/zchee/go-openapi-tools/diag"
+)
+
+// vetAnalyzers is the analyzers of go vet which check the generated code of WithTypeCheck.
+var vetAnalyzers = []*analysis.Analyzer{
+	assign.Analyzer,
+	atomic.Analyzer,
+	bools.Analyzer,
+	copylock.Analyzer,
+	errorsas.Analyzer,
+	httpresponse.Analyzer,
+	loopclosure.Analyzer,
+	lostcancel.Analyzer,
+	nilfunc.Analyzer,
+	printf.Analyzer,
+	shift.Analyzer,
+	stdmethods.Analyzer,
+	stringintconv.Analyzer,
+	structtag.Analyzer,
+	unmarshal.Analyzer,
+	unreachable.Analyzer,
+	unusedresult.Analyzer,
+}
+
+// vetFacts is the facts of the analyzers, which are shared by the generated packages such as the printf wrappers of
+// the package imported by the fake package.
+type vetFacts struct {
+	objects  map[vetFactKey]analysis.Fact
+	packages map[vetFactKey]analysis.Fact
+}
+
+// vetFactKey is the key of the fact, obj is the types.Object or the *types.Package.
+type vetFactKey struct {
+	obj interface{}
+	typ reflect.Type
+}
+
+// newVetFacts returns the empty vetFacts.
+func newVetFacts() *vetFacts {
+	return &vetFacts{
+		objects:  make(map[vetFactKey]analysis.Fact),
+		packages: make(map[vetFactKey]analysis.Fact),
+	}
+}
+
+// importFact copies the fact of obj in m to fact, and reports whether it exists.
+func importFact(m map[vetFactKey]analysis.Fact, obj interface{}, fact analysis.Fact) bool {
+	f, ok := m[vetFactKey{obj, reflect.TypeOf(fact)}]
+	if ok {
+		reflect.ValueOf(fact).Elem().Set(reflect.ValueOf(f).Elem())
+	}
+	return ok
+}
+
+// vet runs vetAnalyzers on the type-checked package, and returns the diagnostics as the errors located in the spec.
+func (g *Generator) vet(fset *token.FileSet, files []*ast.File, pkg *types.Package, info *types.Info, sizes types.Sizes, facts *vetFacts) (diag.List, error) {
+	var errs diag.List
+	results := make(map[*analysis.Analyzer]interface{})
+
+	var run func(a *analysis.Analyzer) (interface{}, error)
+	run = func(a *analysis.Analyzer) (interface{}, error) {
+		if result, ok := results[a]; ok {
+			return result, nil
+		}
+		resultOf := make(map[*analysis.Analyzer]interface{}, len(a.Requires))
+		for _, req := range a.Requires {
+			result, err := run(req)
+			if err != nil {
+				return nil, err
+			}
+			resultOf[req] = result
+		}
+
+		pass := &analysis.Pass{
+			Analyzer:   a,
+			Fset:       fset,
+			Files:      files,
+			Pkg:        pkg,
+			TypesInfo:  info,
+			TypesSizes: sizes,
+			ResultOf:   resultOf,
+			Report: func(d analysis.Diagnostic) {
+				pointer := g.declSource(enclosingDecl(files, d.Pos))
+				errs = append(errs, diag.Errorf(pointer, "%s: %s: %s", fset.Position(d.Pos), a.Name, d.Message))
+			},
+			ReadFile: func(filename string) ([]byte, error) {
+				return nil, &fs.PathError{Op: "read", Path: filename, Err: fs.ErrNotExist} // no other files
+			},
+			ImportObjectFact: func(obj types.Object, fact analysis.Fact) bool {
+				return importFact(facts.objects, obj, fact)
+			},
+			ImportPackageFact: func(pkg *types.Package, fact analysis.Fact) bool {
+				return importFact(facts.packages, pkg, fact)
+			},
+			ExportObjectFact: func(obj types.Object, fact analysis.Fact) {
+				facts.objects[vetFactKey{obj, reflect.TypeOf(fact)}] = fact
+			},
+			ExportPackageFact: func(fact analysis.Fact) {
+				facts.packages[vetFactKey{pkg, reflect.TypeOf(fact)}] = fact
+			},
+			AllObjectFacts: func() []analysis.ObjectFact {
+				var all []analysis.ObjectFact
+				for key, fact := range facts.objects {
+					all = append(all, analysis.ObjectFact{Object: key.obj.(types.Object), Fact: fact})
+				}
+				return all
+			},
+			AllPackageFacts: func() []analysis.PackageFact {
+				var all []analysis.PackageFact
+				for key, fact := range facts.packages {
+					all = append(all, analysis.PackageFact{Package: key.obj.(*types.Package), Fact: fact})
+				}
+				return all
+			},
+		}
+		result, err := a.Run(pass)
+		if err != nil {
+			return nil, fmt.Errorf("failed to run %s analyzer: %w", a.Name, err)
+		}
+		results[a] = result
+
+		return result, nil
+	}
+
+	for _, a := range vetAnalyzers {
+		if _, err := run(a); err != nil {
+			return nil, err
+		}
+	}
+
+	return errs, nil
+}
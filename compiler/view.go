@@ -0,0 +1,202 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+// The types below are the template data of the generated files. The names and types are resolved to the Go
+// identifiers and the Go type expressions before rendering, so the templates only decide the layout of the code.
+
+// FileData is the template data of the generated Go file.
+//
+// Only one of Client, Service, Model, Server, Webhooks and Fake is set, depends on the file.
+type FileData struct {
+	Header   string         // generated code comment
+	Package  string         // package name
+	Title    string         // API name from the package name, such as "Petstore"
+	Imports  []*ImportData  // imported packages, the unreferenced ones are removed and the others are added
+	Patterns []*PatternData // compiled regexp variables which declared in the file
+
+	Client   *ClientData   // client.go
+	Service  *ServiceData  // api_*.go
+	Model    *ModelData    // model_*.go
+	Server   *ServerData   // server.go
+	Webhooks *WebhooksData // webhooks.go
+	Fake     *FakeData     // fake/fake.go
+}
+
+// ImportData represents an imported package.
+type ImportData struct {
+	Path  string // import path
+	Alias string // package name, empty if same as the package name of Path
+}
+
+// PatternData represents the package level variable of the compiled regexp pattern.
+type PatternData struct {
+	Name    string // variable name
+	Pattern string // regexp pattern
+}
+
+// ClientData is the template data of the API client, such as the Service and the schema descriptor.
+type ClientData struct {
+	Version     string         // API version
+	BasePath    string         // default base URL of the API
+	HasBasePath bool           // false if the schema has multiple servers
+	Client      bool           // generates the API client
+	Services    []*ServiceData // child services
+	Descriptor  []string       // Go literal lines of the gzipped JSON schema
+	Size        int            // size of the gzipped JSON schema
+}
+
+// ServiceData is the template data of the child service, such as PetsService.
+type ServiceData struct {
+	Name        string           // Go type name
+	Doc         string           // doc comment without the comment marker, if any
+	Constructor string           // constructor name
+	Interface   string           // interface name, empty if the interface is not generated
+	Operations  []*OperationData // operations sorted by path and HTTP method
+}
+
+// OperationData is the template data of the operation of the child service.
+type OperationData struct {
+	Name            string        // method name of the service
+	Service         string        // Go type name of the service
+	CallType        string        // Call type name
+	ResponseType    string        // Call response type name
+	Summary         string        // lower cased summary ends with dot, if any
+	HTTPMethod      string        // Go constant of the HTTP method, such as "http.MethodGet"
+	Path            string        // path template
+	URI             string        // Go expression of the request path
+	Args            string        // method arguments
+	PathParams      []*ParamData  // path parameters in order of the path template
+	QueryParams     []*ParamData  // query parameters sorted by the name
+	BodyType        string        // Go type of the JSON request body, without pointer, if any
+	ResponseFields  []*FieldData  // properties of the success response
+	ResponseHeaders []*HeaderData // headers of the success response
+	Checks          []*CheckData  // statements of the Validate method
+	FakeField       string        // field name of the fake service which holds the stub
+}
+
+// ParamData represents an operation parameter.
+type ParamData struct {
+	Name     string // parameter name in the schema
+	In       string // location, one of (path, query, header, cookie)
+	Field    string // Go field or argument name
+	Setter   string // query setter method name of the Call
+	Type     string // Go type
+	Required bool
+	Parse    string     // format of the parse expression of the string value, empty if string or decoded as JSON
+	Elem     *ParamData // element of the slice parameter which is parsed from each value, nil if not slice
+	Split    bool       // the values of the slice parameter are comma separated, such as the unexploded query
+}
+
+// FieldData represents a struct field which is encoded as the JSON property.
+type FieldData struct {
+	Name      string // Go field name
+	Type      string // Go type
+	JSON      string // JSON property name
+	OmitEmpty bool   // omitted from JSON if empty
+	Getter    string // getter method name of the model
+}
+
+// HeaderData represents a typed accessor of the response header.
+type HeaderData struct {
+	Name   string // header name
+	Method string // accessor method name
+	Type   string // Go type
+	Parse  string // Go expression which parses the first header value values[0], empty if string
+}
+
+// Kinds of CheckData.
+const (
+	CheckCond   = "cond"   // appends ValidationError if Cond is true
+	CheckGuard  = "guard"  // runs Checks if Cond is true
+	CheckEach   = "each"   // runs Checks for each element v with index i of Expr
+	CheckUnique = "unique" // appends ValidationError if Expr has duplicate elements of Type
+	CheckEnum   = "enum"   // appends ValidationError if Expr is not one of Values
+	CheckModel  = "model"  // validates the non-nil model of Expr
+)
+
+// CheckData represents a statement of the Validate method.
+type CheckData struct {
+	Kind   string       // one of the Check kinds, such as CheckCond
+	Expr   string       // Go expression of the checked value
+	Field  string       // Go expression of the field name of ValidationError
+	Cond   string       // Go condition expression of CheckCond and CheckGuard
+	Reason string       // reason of ValidationError
+	Type   string       // element type of CheckUnique
+	Values []string     // Go literals of CheckEnum
+	Checks []*CheckData // nested statements of CheckGuard and CheckEach
+}
+
+// ModelData is the template data of the model.
+type ModelData struct {
+	Name     string       // Go type name
+	Doc      string       // doc comment without the comment marker
+	Receiver string       // receiver name
+	Type     string       // underlying Go type of the model which is not the object schema, such as "[]*Pet"
+	Alias    bool         // declares Type as the type alias which has no Validate method
+	Consts   []*ConstData // enumerated values of the enum model
+	Fields   []*FieldData // fields of the object schema sorted by the property name
+	Checks   []*CheckData // statements of the Validate method
+}
+
+// ConstData represents a constant of the enumerated value.
+type ConstData struct {
+	Name  string // Go constant name
+	Value string // Go literal
+}
+
+// ServerData is the template data of the server interface and the net/http adapter.
+type ServerData struct {
+	Handlers []*HandlerData // handlers sorted by path and HTTP method
+}
+
+// HandlerData is the template data of the server handler of the operation.
+type HandlerData struct {
+	Name         string          // method name of ServerInterface
+	Adapter      string          // method name of the adapter
+	Method       string          // upper cased HTTP method
+	Path         string          // path template
+	Pattern      string          // http.ServeMux pattern
+	Summary      string          // lower cased summary ends with dot, if any
+	PathParams   []*ParamData    // path parameters sorted by the name
+	Params       []*ParamData    // query, header and cookie parameters
+	ParamsType   string          // struct type name of Params, empty if no Params
+	BodyType     string          // Go type of the JSON request body, without pointer, if any
+	BodyRequired bool            // the request body is required, the optional body is nil if the request has no body
+	StatusCode   int             // status code of the response which has no StatusCode
+	Responses    []*ResponseData // typed response constructors sorted by the status code
+}
+
+// ResponseData is the template data of the typed response constructor.
+type ResponseData struct {
+	Func     string // function name
+	Code     string // status code, empty if the default response
+	Status   string // status text of Code
+	BodyType string // Go type of the JSON response body, if any
+}
+
+// WebhooksData is the template data of the receiver handlers of the webhooks and callbacks.
+type WebhooksData struct {
+	Receivers []*ReceiverData // webhooks sorted by the name and HTTP method, followed by the callbacks
+}
+
+// ReceiverData is the template data of the receiver handler of the webhook or callback.
+type ReceiverData struct {
+	Name       string // webhook or callback name
+	Expression string // runtime expression of the callback URL, empty if webhook
+	Callback   string // method name of the operation which declares the callback, empty if webhook
+	Method     string // upper cased HTTP method
+	Summary    string // lower cased summary ends with dot, if any
+	FuncType   string // function type which handles the payload
+	Handler    string // constructor name of the http.Handler
+	BodyType   string // Go type of the JSON payload, without pointer, if any
+	StatusCode int    // status code of the success response
+}
+
+// FakeData is the template data of the fake subpackage.
+type FakeData struct {
+	Package  string         // package name of the generated package
+	Import   *ImportData    // import of the generated package
+	Services []*ServiceData // services of the generated package
+}
@@ -0,0 +1,93 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package compiler
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/zchee/go-openapi-tools/diag"
+	"github.com/zchee/go-openapi-tools/ir"
+)
+
+// generatesWebhooks reports whether the receiver handlers of the webhooks and callbacks are generated, which decode the
+// payloads into the models.
+func (g *Generator) generatesWebhooks() bool {
+	return g.api != nil && len(g.api.Webhooks) > 0 && !g.skipModels
+}
+
+// webhookPointer returns the JSON pointer of the webhook or callback operation.
+func webhookPointer(w *ir.Webhook) string {
+	method := strings.ToLower(w.Operation.Method)
+	if w.Callback == nil {
+		return diag.Pointer("webhooks", w.Name, method)
+	}
+
+	return operationPointer(w.Callback) + strings.TrimPrefix(diag.Pointer("callbacks", w.Name, w.Expression, method), "#")
+}
+
+// buildWebhooks returns the template data of the receiver handlers of the webhooks and callbacks.
+func (g *Generator) buildWebhooks() *WebhooksData {
+	// the receivers are named by the HTTP method too if the webhook or callback has multiple operations
+	methods := make(map[string]int)
+	for _, w := range g.api.Webhooks {
+		methods[webhookKey(w)]++
+	}
+
+	webhooks := new(WebhooksData)
+	for _, w := range g.api.Webhooks {
+		op := w.Operation
+		var base string
+		switch {
+		case op.ID != "":
+			base = g.depunct(op.ID, true)
+		default:
+			base = g.depunct(w.Name, true)
+			if w.Callback != nil {
+				base = g.operationName(w.Callback) + base
+			}
+			if methods[webhookKey(w)] > 1 {
+				base += g.depunct(strings.ToLower(op.Method), true)
+			}
+		}
+
+		r := &ReceiverData{
+			Name:       w.Name,
+			Expression: w.Expression,
+			Method:     op.Method,
+			Summary:    sentence(op.Summary),
+			BodyType:   g.requestBodyType(op),
+			StatusCode: successCode(op),
+		}
+		if w.Callback != nil {
+			r.Callback = g.operationName(w.Callback)
+		}
+		r.FuncType, r.Handler = g.webhookTypes(w, base)
+		g.declareSource(r.FuncType, webhookPointer(w))
+		g.declareSource(r.Handler, webhookPointer(w))
+		webhooks.Receivers = append(webhooks.Receivers, r)
+	}
+
+	return webhooks
+}
+
+// webhookKey returns the key of the webhook or callback, which has the operations of the HTTP methods.
+func webhookKey(w *ir.Webhook) string {
+	if w.Callback == nil {
+		return "webhook " + w.Name
+	}
+
+	return "callback " + operationPointer(w.Callback) + " " + w.Name + " " + w.Expression
+}
+
+// successCode returns the first 2xx status code of the responses of op, or 200 OK if none.
+func successCode(op *ir.Operation) int {
+	for _, resp := range op.Responses {
+		if code := resp.Code; len(code) == 3 && code[0] == '2' && IsDigit(code[1]) && IsDigit(code[2]) {
+			return statusCode(code)
+		}
+	}
+
+	return http.StatusOK
+}
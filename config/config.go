@@ -0,0 +1,246 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package config provides the oapi-generator configuration file, such as oapi-generator.yaml.
+//
+// The configuration is validated against the JSON Schema, which is available as Schema for the editor support.
+package config
+
+import (
+	"bytes"
+	_ "embed" // for Schema
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/ghodss/yaml"
+	json "github.com/goccy/go-json"
+
+	"github.com/zchee/go-openapi-tools/internal/srcmap"
+)
+
+// FileName is the default file name of the configuration.
+const FileName = "oapi-generator.yaml"
+
+// Schema is the JSON Schema of the configuration.
+//
+//go:embed schema.json
+var Schema []byte
+
+// Config represents the oapi-generator configuration.
+type Config struct {
+	// Specs is the schemas to generate.
+	Specs []*Spec `json:"specs"`
+}
+
+// Spec represents the generation of a schema.
+type Spec struct {
+	// Schema is the path of the schema file. Relative path is resolved from the configuration file by Load.
+	Schema string `json:"schema"`
+	// SchemaType is the schema type, one of (openapi, swagger). Overrides the detection from the version field of the
+	// schema, which is used if empty.
+	SchemaType string `json:"schemaType,omitempty"`
+	// Package is the package name of the generated code.
+	Package string `json:"package,omitempty"`
+	// Out is the output directory. Relative path is resolved from the configuration file by Load, and defaults to the
+	// directory of the configuration file.
+	Out string `json:"out,omitempty"`
+	// ImportPath is the import path of the generated package. Detected from the go.mod if empty.
+	ImportPath string `json:"importPath,omitempty"`
+	// Clean removes the stale generated files before generation.
+	Clean bool `json:"clean,omitempty"`
+	// Include generates only the operations which match any of the rules.
+	Include *Filter `json:"include,omitempty"`
+	// Exclude does not generate the operations which match any of the rules.
+	Exclude *Filter `json:"exclude,omitempty"`
+	// TagMode assigns the operation which has multiple tags to the services, one of (first, all).
+	TagMode string `json:"tagMode,omitempty"`
+	// TypeMappings maps the schema type such as "string:uuid" to the Go type such as "github.com/google/uuid.UUID".
+	TypeMappings map[string]string `json:"typeMappings,omitempty"`
+	// Naming is the naming rules.
+	Naming *Naming `json:"naming,omitempty"`
+	// Templates is the directory of the *.tmpl files which override the default templates of the generated code.
+	// Relative path is resolved from the configuration file by Load.
+	Templates string `json:"templates,omitempty"`
+	// TypeCheck type-checks and vets the generated code before writing the files.
+	TypeCheck bool `json:"typeCheck,omitempty"`
+	// Generate is the artifacts to generate.
+	Generate *Artifacts `json:"generate,omitempty"`
+}
+
+// Filter represents the rules to select the operations.
+type Filter struct {
+	Tags       []string `json:"tags,omitempty"`
+	Operations []string `json:"operations,omitempty"`
+	Paths      []string `json:"paths,omitempty"`
+}
+
+// Naming represents the naming rules.
+type Naming struct {
+	// Initialisms is the additional initialisms such as "OAI".
+	Initialisms []string `json:"initialisms,omitempty"`
+	// Operations maps the operation ID, or the HTTP method and path such as "GET /pets/{id}", to the Go method name.
+	Operations map[string]string `json:"operations,omitempty"`
+	// TrimGetPrefix trims the "Get" prefix of the Go method names from the operation IDs. defaults to true.
+	TrimGetPrefix *bool `json:"trimGetPrefix,omitempty"`
+}
+
+// Artifacts represents the artifacts to generate.
+type Artifacts struct {
+	Client     *bool `json:"client,omitempty"` // defaults to true
+	Models     *bool `json:"models,omitempty"` // defaults to true
+	Server     bool  `json:"server,omitempty"`
+	Interfaces bool  `json:"interfaces,omitempty"`
+	Mocks      bool  `json:"mocks,omitempty"`
+}
+
+// GenerateClient reports whether to generate the API client.
+func (a *Artifacts) GenerateClient() bool {
+	return a == nil || a.Client == nil || *a.Client
+}
+
+// GenerateModels reports whether to generate the models.
+func (a *Artifacts) GenerateModels() bool {
+	return a == nil || a.Models == nil || *a.Models
+}
+
+// schema is the parsed Schema.
+var schema = func() *openapi3.Schema {
+	s := openapi3.NewSchema()
+	if err := s.UnmarshalJSON(Schema); err != nil {
+		panic(fmt.Sprintf("config: invalid schema.json: %v", err))
+	}
+	return s
+}()
+
+// Load loads the configuration file and validates it against Schema.
+//
+// The relative paths in the configuration are resolved from the directory of filename, and the omitted output
+// directory defaults to the directory of filename.
+func Load(filename string) (*Config, error) {
+	data, err := os.ReadFile(filename)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg, err := parse(filename, data)
+	if err != nil {
+		return nil, err
+	}
+
+	dir := filepath.Dir(filename)
+	for _, spec := range cfg.Specs {
+		if !filepath.IsAbs(spec.Schema) {
+			spec.Schema = filepath.Join(dir, spec.Schema)
+		}
+		if !filepath.IsAbs(spec.Out) {
+			spec.Out = filepath.Join(dir, spec.Out)
+		}
+		if spec.Templates != "" && !filepath.IsAbs(spec.Templates) {
+			spec.Templates = filepath.Join(dir, spec.Templates)
+		}
+	}
+
+	return cfg, nil
+}
+
+// Error represents the invalid configuration at the position of the configuration file.
+type Error struct {
+	Filename string
+	Pos      srcmap.Position
+	Pointer  string // JSON pointer to the invalid value
+	Reason   string
+}
+
+// Error implements error.
+func (e *Error) Error() string {
+	var sb strings.Builder
+	sb.WriteString(e.Filename)
+	if e.Pos.IsValid() {
+		sb.WriteString(":" + e.Pos.String())
+	}
+	sb.WriteString(": ")
+	if e.Pointer != "" {
+		sb.WriteString(e.Pointer + ": ")
+	}
+	sb.WriteString(e.Reason)
+
+	return sb.String()
+}
+
+// Errors is the aggregated Error.
+type Errors []*Error
+
+// Error implements error.
+func (errs Errors) Error() string {
+	s := make([]string, len(errs))
+	for i, err := range errs {
+		s[i] = err.Error()
+	}
+
+	return strings.Join(s, "\n")
+}
+
+// parse parses the YAML or JSON data of the configuration file.
+func parse(filename string, data []byte) (*Config, error) {
+	sm, err := srcmap.Parse(data)
+	if err != nil {
+		return nil, &Error{Filename: filename, Reason: err.Error()}
+	}
+
+	jsonData, err := yaml.YAMLToJSON(data)
+	if err != nil {
+		return nil, &Error{Filename: filename, Reason: err.Error()}
+	}
+
+	var v interface{}
+	if err := json.Unmarshal(jsonData, &v); err != nil {
+		return nil, &Error{Filename: filename, Reason: err.Error()}
+	}
+	if err := schema.VisitJSON(v, openapi3.MultiErrors()); err != nil {
+		return nil, schemaErrors(filename, sm, err)
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(jsonData))
+	dec.DisallowUnknownFields()
+	var cfg Config
+	if err := dec.Decode(&cfg); err != nil {
+		return nil, &Error{Filename: filename, Reason: err.Error()}
+	}
+
+	return &cfg, nil
+}
+
+// schemaErrors converts the validation error of Schema to Errors.
+func schemaErrors(filename string, sm srcmap.Map, err error) error {
+	var errs Errors
+
+	var walk func(err error)
+	walk = func(err error) {
+		var merr openapi3.MultiError
+		if errors.As(err, &merr) {
+			for _, e := range merr {
+				walk(e)
+			}
+			return
+		}
+
+		e := &Error{Filename: filename, Reason: err.Error()}
+		var serr *openapi3.SchemaError
+		if errors.As(err, &serr) {
+			e.Pointer = srcmap.Pointer(serr.JSONPointer()...)
+			e.Reason = serr.Reason
+			if e.Reason == "" {
+				e.Reason = fmt.Sprintf("doesn't match schema %q", serr.SchemaField)
+			}
+		}
+		e.Pos = sm.Lookup(e.Pointer)
+		errs = append(errs, e)
+	}
+	walk(err)
+
+	return errs
+}
@@ -0,0 +1,99 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package config
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := map[string]struct {
+		data    string
+		want    *Config
+		wantErr string
+	}{
+		"Minimal": {
+			data: "specs:\n  - schema: petstore.yaml\n",
+			want: &Config{Specs: []*Spec{{Schema: "petstore.yaml"}}},
+		},
+		"Naming": {
+			data: "specs:\n  - schema: petstore.yaml\n    naming:\n      initialisms: [OAI]\n      operations:\n        listPets: AllPets\n",
+			want: &Config{Specs: []*Spec{{
+				Schema: "petstore.yaml",
+				Naming: &Naming{Initialisms: []string{"OAI"}, Operations: map[string]string{"listPets": "AllPets"}},
+			}}},
+		},
+		"JSON": {
+			data: `{"specs":[{"schema":"petstore.json","package":"petstore","clean":true}]}`,
+			want: &Config{Specs: []*Spec{{Schema: "petstore.json", Package: "petstore", Clean: true}}},
+		},
+		"NoSpecs": {
+			data:    "specs: []\n",
+			wantErr: "oapi-generator.yaml:1:1: /specs: minimum number of items is 1",
+		},
+		"InvalidPackage": {
+			data:    "specs:\n  - schema: petstore.yaml\n    package: Pet-Store\n",
+			wantErr: "oapi-generator.yaml:3:5: /specs/0/package: ",
+		},
+		"UnknownField": {
+			data:    "specs:\n  - schema: petstore.yaml\n    output: gen\n",
+			wantErr: "oapi-generator.yaml:2:",
+		},
+		"InvalidYAML": {
+			data:    "specs: [\n",
+			wantErr: "oapi-generator.yaml: ",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got, err := parse(FileName, []byte(tt.data))
+			if tt.wantErr != "" {
+				if err == nil || !strings.HasPrefix(err.Error(), tt.wantErr) {
+					t.Fatalf("parse() error = %v, want the prefix %q", err, tt.wantErr)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Fatalf("parse() = %+v, want %+v", got.Specs[0], tt.want.Specs[0])
+			}
+		})
+	}
+}
+
+func TestLoad(t *testing.T) {
+	dir := t.TempDir()
+	filename := filepath.Join(dir, FileName)
+	data := "specs:\n  - schema: spec/petstore.yaml\n    out: gen\n    templates: templates\n  - schema: /abs/petstore.yaml\n"
+	if err := os.WriteFile(filename, []byte(data), 0666); err != nil {
+		t.Fatal(err)
+	}
+
+	cfg, err := Load(filename)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []*Spec{
+		{Schema: filepath.Join(dir, "spec", "petstore.yaml"), Out: filepath.Join(dir, "gen"), Templates: filepath.Join(dir, "templates")},
+		// the omitted out defaults to the directory of the configuration file, not the current directory
+		{Schema: "/abs/petstore.yaml", Out: dir},
+	}
+	if !reflect.DeepEqual(cfg.Specs, want) {
+		t.Fatalf("Load() = %+v, want %+v", cfg.Specs, want)
+	}
+
+	if _, err := Load(filepath.Join(dir, "missing.yaml")); !errors.Is(err, os.ErrNotExist) {
+		t.Fatalf("Load() of the missing file error = %v, want %v", err, os.ErrNotExist)
+	}
+}
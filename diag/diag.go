@@ -0,0 +1,220 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package diag provides the diagnostics of the schema and the generator, which are located in the schema by the JSON
+// pointers and the source positions.
+package diag
+
+import (
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+
+	"github.com/zchee/go-openapi-tools/internal/srcmap"
+)
+
+// Severity represents the severity of the Diagnostic.
+type Severity int
+
+const (
+	// Error is the diagnostic which fails the generation.
+	Error Severity = iota
+	// Warning is the diagnostic of the schema which is generated differently than it means, such as the skipped
+	// property.
+	Warning
+	// Info is the informational diagnostic, such as the renamed identifier.
+	Info
+)
+
+// String returns a string representation of the Severity.
+func (s Severity) String() string {
+	switch s {
+	case Error:
+		return "error"
+	case Warning:
+		return "warning"
+	default:
+		return "info"
+	}
+}
+
+// Level returns the log level of the Severity, which filters the diagnostics by the verbosity.
+func (s Severity) Level() slog.Level {
+	switch s {
+	case Error:
+		return slog.LevelError
+	case Warning:
+		return slog.LevelWarn
+	default:
+		return slog.LevelInfo
+	}
+}
+
+// MarshalText implements encoding.TextMarshaler.
+func (s Severity) MarshalText() ([]byte, error) {
+	return []byte(s.String()), nil
+}
+
+// Diagnostic represents an error or warning at the location of the schema.
+type Diagnostic struct {
+	Severity Severity `json:"severity"`
+	Msg      string   `json:"message"`
+	Pointer  string   `json:"pointer,omitempty"` // JSON pointer of the schema location such as "#/components/schemas/Pet"
+	File     string   `json:"file,omitempty"`    // schema file name, empty if unknown
+	Line     int      `json:"line,omitempty"`    // 1-based line number of Pointer in File, 0 if unknown
+	Column   int      `json:"column,omitempty"`  // 1-based column number of Pointer in File, 0 if unknown
+}
+
+// Errorf returns the Error diagnostic at pointer.
+func Errorf(pointer, format string, args ...interface{}) *Diagnostic {
+	return &Diagnostic{Severity: Error, Msg: fmt.Sprintf(format, args...), Pointer: pointer}
+}
+
+// Warnf returns the Warning diagnostic at pointer.
+func Warnf(pointer, format string, args ...interface{}) *Diagnostic {
+	return &Diagnostic{Severity: Warning, Msg: fmt.Sprintf(format, args...), Pointer: pointer}
+}
+
+// Infof returns the Info diagnostic at pointer.
+func Infof(pointer, format string, args ...interface{}) *Diagnostic {
+	return &Diagnostic{Severity: Info, Msg: fmt.Sprintf(format, args...), Pointer: pointer}
+}
+
+// Pointer returns the JSON pointer of the reference tokens, such as "#/paths/~1pets~1{id}/get".
+func Pointer(tokens ...string) string {
+	return "#" + srcmap.Pointer(tokens...)
+}
+
+// Position returns the position of d, such as "petstore.yaml:12:5". The line and column are omitted if unknown.
+func (d *Diagnostic) Position() string {
+	if d.Line == 0 {
+		return d.File
+	}
+
+	return fmt.Sprintf("%s:%d:%d", d.File, d.Line, d.Column)
+}
+
+// String returns a string representation of the Diagnostic, such as
+// "petstore.yaml:12:5: warning: message (#/components/schemas/Pet)".
+func (d *Diagnostic) String() string {
+	var sb strings.Builder
+	if pos := d.Position(); pos != "" {
+		sb.WriteString(pos + ": ")
+	}
+	sb.WriteString(d.Severity.String() + ": " + d.Msg)
+	if d.Pointer != "" {
+		sb.WriteString(" (" + d.Pointer + ")")
+	}
+
+	return sb.String()
+}
+
+// Error implements error.
+func (d *Diagnostic) Error() string {
+	return d.String()
+}
+
+// List is the list of Diagnostic, which is also the error of the Error diagnostics.
+type List []*Diagnostic
+
+// Error implements error.
+func (l List) Error() string {
+	switch len(l) {
+	case 0:
+		return "no diagnostics"
+	case 1:
+		return l[0].String()
+	}
+
+	msgs := make([]string, len(l))
+	for i, d := range l {
+		msgs[i] = d.String()
+	}
+
+	return fmt.Sprintf("%d diagnostics:\n%s", len(l), strings.Join(msgs, "\n"))
+}
+
+// HasErrors reports whether l has the Error diagnostics.
+func (l List) HasErrors() bool {
+	for _, d := range l {
+		if d.Severity == Error {
+			return true
+		}
+	}
+
+	return false
+}
+
+// Filter returns the diagnostics of l which are enabled by the log level, such as the errors and warnings of
+// slog.LevelWarn.
+func (l List) Filter(level slog.Level) List {
+	var filtered List
+	for _, d := range l {
+		if d.Severity.Level() >= level {
+			filtered = append(filtered, d)
+		}
+	}
+
+	return filtered
+}
+
+// Sort sorts l by the file and the position, the diagnostics of the unknown position are kept first.
+func (l List) Sort() {
+	sort.SliceStable(l, func(i, j int) bool {
+		if l[i].File != l[j].File {
+			return l[i].File < l[j].File
+		}
+		if l[i].Line != l[j].Line {
+			return l[i].Line < l[j].Line
+		}
+		return l[i].Column < l[j].Column
+	})
+}
+
+// Locate sets the file and the source positions of the pointers of l in the JSON or YAML data of the schema file.
+//
+// pointer translates the pointers of l to the pointers of data, such as SwaggerPointer. It is ignored if nil.
+// The positions of the pointers which not exist in data are the nearest existing parents.
+func (l List) Locate(file string, data []byte, pointer func(string) string) {
+	var sm srcmap.Map
+	if len(data) > 0 {
+		sm, _ = srcmap.Parse(data) // the positions are unknown if failed, the schema is already decoded
+	}
+
+	for _, d := range l {
+		if d.File == "" {
+			d.File = file
+		}
+		if d.Line != 0 || d.Pointer == "" || sm == nil {
+			continue
+		}
+		p := d.Pointer
+		if pointer != nil {
+			p = pointer(p)
+		}
+		pos := sm.Lookup(strings.TrimPrefix(p, "#"))
+		d.Line, d.Column = pos.Line, pos.Column
+	}
+}
+
+// SwaggerPointer translates the JSON pointer of the OpenAPI 3.0 schema converted from Swagger 2.0 to the original.
+func SwaggerPointer(pointer string) string {
+	prefix := ""
+	if strings.HasPrefix(pointer, "#") {
+		prefix, pointer = "#", pointer[1:]
+	}
+
+	for from, to := range map[string]string{
+		"/components/schemas/":         "/definitions/",
+		"/components/parameters/":      "/parameters/",
+		"/components/responses/":       "/responses/",
+		"/components/securitySchemes/": "/securityDefinitions/",
+	} {
+		if strings.HasPrefix(pointer, from) {
+			return prefix + to + strings.TrimPrefix(pointer, from)
+		}
+	}
+
+	return prefix + pointer
+}
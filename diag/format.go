@@ -0,0 +1,169 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package diag
+
+import (
+	"fmt"
+	"io"
+	"path/filepath"
+
+	json "github.com/goccy/go-json"
+)
+
+// The output formats of Write.
+const (
+	FormatText  = "text"
+	FormatJSON  = "json"
+	FormatSARIF = "sarif"
+)
+
+// Formats is the list of the output formats of Write.
+var Formats = []string{FormatText, FormatJSON, FormatSARIF}
+
+// Write writes the diagnostics to w in format, one of Formats.
+//
+// The text format writes a line per Diagnostic, and writes nothing if l is empty. The JSON format writes the array of
+// Diagnostic, and the SARIF format writes the SARIF 2.1.0 log which is used for the code scanning annotations of CI.
+func Write(w io.Writer, format string, l List) error {
+	switch format {
+	case FormatText, "":
+		for _, d := range l {
+			if _, err := fmt.Fprintln(w, d); err != nil {
+				return err
+			}
+		}
+		return nil
+
+	case FormatJSON:
+		if l == nil {
+			l = List{}
+		}
+		return writeJSON(w, l)
+
+	case FormatSARIF:
+		return writeJSON(w, sarifLog(l))
+
+	default:
+		return fmt.Errorf("unknown diagnostics format %q, one of %v", format, Formats)
+	}
+}
+
+// writeJSON writes the indented JSON of v to w.
+func writeJSON(w io.Writer, v interface{}) error {
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(v); err != nil {
+		return fmt.Errorf("failed to write diagnostics: %w", err)
+	}
+
+	return nil
+}
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	toolName     = "oapi-generator"
+	toolURI      = "https://github.com/zchee/go-openapi-tools"
+)
+
+// SARIF 2.1.0 objects, only the properties which used by the diagnostics.
+type (
+	sarif struct {
+		Version string     `json:"version"`
+		Schema  string     `json:"$schema"`
+		Runs    []sarifRun `json:"runs"`
+	}
+
+	sarifRun struct {
+		Tool    sarifTool     `json:"tool"`
+		Results []sarifResult `json:"results"`
+	}
+
+	sarifTool struct {
+		Driver sarifDriver `json:"driver"`
+	}
+
+	sarifDriver struct {
+		Name           string `json:"name"`
+		InformationURI string `json:"informationUri"`
+	}
+
+	sarifResult struct {
+		Level     string          `json:"level"`
+		Message   sarifMessage    `json:"message"`
+		Locations []sarifLocation `json:"locations,omitempty"`
+	}
+
+	sarifMessage struct {
+		Text string `json:"text"`
+	}
+
+	sarifLocation struct {
+		PhysicalLocation *sarifPhysicalLocation `json:"physicalLocation,omitempty"`
+		LogicalLocations []sarifLogicalLocation `json:"logicalLocations,omitempty"`
+	}
+
+	sarifPhysicalLocation struct {
+		ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+		Region           *sarifRegion          `json:"region,omitempty"`
+	}
+
+	sarifArtifactLocation struct {
+		URI string `json:"uri"`
+	}
+
+	sarifRegion struct {
+		StartLine   int `json:"startLine"`
+		StartColumn int `json:"startColumn,omitempty"`
+	}
+
+	sarifLogicalLocation struct {
+		FullyQualifiedName string `json:"fullyQualifiedName"`
+	}
+)
+
+// sarifLevels is the SARIF result levels of the severities.
+var sarifLevels = map[Severity]string{
+	Error:   "error",
+	Warning: "warning",
+	Info:    "note",
+}
+
+// sarifLog returns the SARIF log of the diagnostics, which has a run of the generator.
+func sarifLog(l List) *sarif {
+	results := make([]sarifResult, 0, len(l))
+	for _, d := range l {
+		result := sarifResult{
+			Level:   sarifLevels[d.Severity],
+			Message: sarifMessage{Text: d.Msg},
+		}
+
+		var loc sarifLocation
+		if d.File != "" {
+			loc.PhysicalLocation = &sarifPhysicalLocation{
+				ArtifactLocation: sarifArtifactLocation{URI: filepath.ToSlash(d.File)},
+			}
+			if d.Line > 0 {
+				loc.PhysicalLocation.Region = &sarifRegion{StartLine: d.Line, StartColumn: d.Column}
+			}
+		}
+		if d.Pointer != "" {
+			loc.LogicalLocations = []sarifLogicalLocation{{FullyQualifiedName: d.Pointer}}
+		}
+		if loc.PhysicalLocation != nil || loc.LogicalLocations != nil {
+			result.Locations = []sarifLocation{loc}
+		}
+
+		results = append(results, result)
+	}
+
+	return &sarif{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs: []sarifRun{{
+			Tool:    sarifTool{Driver: sarifDriver{Name: toolName, InformationURI: toolURI}},
+			Results: results,
+		}},
+	}
+}
@@ -1,16 +1,18 @@
 module github.com/zchee/go-openapi-tools
 
-go 1.17
+go 1.21
 
 require (
 	github.com/getkin/kin-openapi v0.89.0
+	github.com/ghodss/yaml v1.0.0
 	github.com/goccy/go-json v0.9.4
 	github.com/iancoleman/strcase v0.2.0
 	github.com/klauspost/compress v1.14.2
+	golang.org/x/tools v0.24.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
-	github.com/ghodss/yaml v1.0.0 // indirect
 	github.com/go-openapi/jsonpointer v0.19.5 // indirect
 	github.com/go-openapi/swag v0.19.5 // indirect
 	github.com/mailru/easyjson v0.0.0-20190626092158-b2ccc519800e // indirect
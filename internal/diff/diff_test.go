@@ -0,0 +1,125 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package diff
+
+import (
+	"math/rand"
+	"strings"
+	"testing"
+)
+
+func TestUnified(t *testing.T) {
+	const lines = "1\n2\n3\n4\n5\n6\n7\n8\n9\n10\n11\n12\n13\n14\n15\n16\n17\n18\n19\n20\n"
+
+	tests := map[string]struct {
+		old  string
+		new  string
+		want string
+	}{
+		"Same": {
+			old:  "a\nb\n",
+			new:  "a\nb\n",
+			want: "",
+		},
+		"Change": {
+			old:  "a\nb\nc\n",
+			new:  "a\nB\nc\n",
+			want: "--- a/x\n+++ b/x\n@@ -1,3 +1,3 @@\n a\n-b\n+B\n c\n",
+		},
+		"NoNewlineAtEOF": {
+			old:  "a\nb\n",
+			new:  "a\nb\nc",
+			want: "--- a/x\n+++ b/x\n@@ -1,2 +1,3 @@\n a\n b\n+c\n\\ No newline at end of file\n",
+		},
+		"NewFile": {
+			old:  "",
+			new:  "a\nb\n",
+			want: "--- a/x\n+++ b/x\n@@ -0,0 +1,2 @@\n+a\n+b\n",
+		},
+		"RemovedFile": {
+			old:  "a\nb\n",
+			new:  "",
+			want: "--- a/x\n+++ b/x\n@@ -1,2 +0,0 @@\n-a\n-b\n",
+		},
+		"Hunks": {
+			old:  lines,
+			new:  strings.Replace(strings.Replace(lines, "\n2\n", "\nX\n", 1), "\n19\n", "\nY\n", 1),
+			want: "--- a/x\n+++ b/x\n@@ -1,5 +1,5 @@\n 1\n-2\n+X\n 3\n 4\n 5\n@@ -16,5 +16,5 @@\n 16\n 17\n 18\n-19\n+Y\n 20\n",
+		},
+		"MergedHunk": {
+			old:  lines,
+			new:  strings.Replace(strings.Replace(lines, "\n2\n", "\nX\n", 1), "\n8\n", "\nY\n", 1),
+			want: "--- a/x\n+++ b/x\n@@ -1,11 +1,11 @@\n 1\n-2\n+X\n 3\n 4\n 5\n 6\n 7\n-8\n+Y\n 9\n 10\n 11\n",
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			got := string(Unified("a/x", "b/x", []byte(tt.old), []byte(tt.new)))
+			if got != tt.want {
+				t.Fatalf("Unified() =\n%s\nwant:\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestEdits(t *testing.T) {
+	rnd := rand.New(rand.NewSource(1))
+	randomLines := func() []string {
+		lines := make([]string, rnd.Intn(20))
+		for i := range lines {
+			lines[i] = string(rune('a'+rnd.Intn(4))) + "\n"
+		}
+		return lines
+	}
+
+	for i := 0; i < 500; i++ {
+		a, b := randomLines(), randomLines()
+		ops := edits(a, b)
+
+		var gotA, gotB []string
+		changes := 0
+		for _, o := range ops {
+			if o.kind != opInsert {
+				gotA = append(gotA, o.line)
+			}
+			if o.kind != opDelete {
+				gotB = append(gotB, o.line)
+			}
+			if o.kind != opEqual {
+				changes++
+			}
+		}
+		if strings.Join(gotA, "") != strings.Join(a, "") || strings.Join(gotB, "") != strings.Join(b, "") {
+			t.Fatalf("edits(%q, %q) = %v, which does not rebuild the inputs", a, b, ops)
+		}
+		if want := len(a) + len(b) - 2*lcs(a, b); changes != want {
+			t.Fatalf("edits(%q, %q) has %d changes, want the shortest %d", a, b, changes, want)
+		}
+	}
+}
+
+// lcs returns the length of the longest common subsequence of a and b.
+func lcs(a, b []string) int {
+	dp := make([][]int, len(a)+1)
+	for i := range dp {
+		dp[i] = make([]int, len(b)+1)
+	}
+	for i := len(a) - 1; i >= 0; i-- {
+		for j := len(b) - 1; j >= 0; j-- {
+			switch {
+			case a[i] == b[j]:
+				dp[i][j] = dp[i+1][j+1] + 1
+			case dp[i+1][j] > dp[i][j+1]:
+				dp[i][j] = dp[i+1][j]
+			default:
+				dp[i][j] = dp[i][j+1]
+			}
+		}
+	}
+
+	return dp[0][0]
+}
@@ -0,0 +1,304 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package openapi31 converts the OpenAPI 3.1 schema to OpenAPI 3.0, which the generator and the validator load.
+//
+// The JSON Schema 2020-12 keywords of OpenAPI 3.1 are translated to the OpenAPI 3.0 equivalents, such as the type
+// arrays to the nullable schemas and the $defs to the component schemas. The keywords which can not be represented in
+// OpenAPI 3.0 are removed, and reported as the warnings.
+package openapi31
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ghodss/yaml"
+	json "github.com/goccy/go-json"
+
+	"github.com/zchee/go-openapi-tools/diag"
+	"github.com/zchee/go-openapi-tools/internal/srcmap"
+)
+
+// Version is the OpenAPI version of the converted schema.
+const Version = "3.0.3"
+
+// WebhooksExtension is the extension of the converted schema which has the webhooks of the OpenAPI 3.1 schema, those
+// are the path items keyed by the webhook names. The generator generates the receiver handlers of the webhooks.
+const WebhooksExtension = "x-webhooks"
+
+// versionRe matches the OpenAPI 3.1 versions.
+var versionRe = regexp.MustCompile(`^3\.1\.\d+$`)
+
+// IsVersion reports whether the version of the openapi field is OpenAPI 3.1.
+func IsVersion(version string) bool {
+	return versionRe.MatchString(version)
+}
+
+// Convert converts the JSON or YAML OpenAPI 3.1 schema data to the JSON OpenAPI 3.0 schema.
+//
+// The warnings are located by the JSON pointers of data.
+func Convert(data []byte) ([]byte, diag.List, error) {
+	if !json.Valid(data) {
+		var err error
+		if data, err = yaml.YAMLToJSON(data); err != nil {
+			return nil, nil, fmt.Errorf("failed to convert YAML schema to JSON: %w", err)
+		}
+	}
+
+	dec := json.NewDecoder(bytes.NewReader(data))
+	dec.UseNumber() // keeps the integers which float64 can not represent
+	var doc map[string]interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, nil, fmt.Errorf("failed to decode OpenAPI 3.1 schema: %w", err)
+	}
+
+	c := &converter{
+		doc:  doc,
+		refs: make(map[string]string),
+	}
+	c.convert()
+
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to encode OpenAPI 3.0 schema: %w", err)
+	}
+
+	return out, c.diags, nil
+}
+
+// converter converts the decoded OpenAPI 3.1 document in place.
+type converter struct {
+	doc   map[string]interface{}
+	diags diag.List
+
+	defs []*def            // $defs which are moved to the component schemas
+	refs map[string]string // $ref of the $defs to the $ref of the component schemas
+}
+
+// def is the schema of $defs, which is moved to the component schemas.
+type def struct {
+	name    string
+	schema  interface{}
+	pointer string
+}
+
+// warnf records the warning at pointer.
+func (c *converter) warnf(pointer, format string, args ...interface{}) {
+	c.diags = append(c.diags, diag.Warnf(pointer, format, args...))
+}
+
+// convert converts the document.
+func (c *converter) convert() {
+	c.doc["openapi"] = Version
+	delete(c.doc, "jsonSchemaDialect")
+
+	if info := object(c.doc["info"]); info != nil {
+		delete(info, "summary")
+		if license := object(info["license"]); license != nil {
+			delete(license, "identifier")
+		}
+	}
+
+	components := object(c.doc["components"])
+	pathItems := object(components["pathItems"])
+	delete(components, "pathItems")
+
+	paths := object(c.doc["paths"])
+	if paths == nil {
+		paths = make(map[string]interface{})
+		c.doc["paths"] = paths
+	}
+	for _, path := range sortedKeys(paths) {
+		paths[path] = c.pathItem(inlinePathItem(paths[path], pathItems), pointer("#", "paths", path))
+	}
+
+	if webhooks := object(c.doc["webhooks"]); webhooks != nil {
+		for _, name := range sortedKeys(webhooks) {
+			webhooks[name] = c.pathItem(inlinePathItem(webhooks[name], pathItems), pointer("#", "webhooks", name))
+		}
+		c.doc[WebhooksExtension] = webhooks
+		delete(c.doc, "webhooks")
+	}
+
+	if components != nil {
+		c.components(components)
+	}
+	c.moveDefs()
+	c.rewriteRefs(c.doc)
+}
+
+// components converts the schemas of the components.
+func (c *converter) components(components map[string]interface{}) {
+	p := pointer("#", "components")
+
+	schemas := object(components["schemas"])
+	for _, name := range sortedKeys(schemas) {
+		c.schema(schemas[name], pointer(p, "schemas", name))
+	}
+	parameters := object(components["parameters"])
+	for _, name := range sortedKeys(parameters) {
+		c.parameter(parameters[name], pointer(p, "parameters", name))
+	}
+	headers := object(components["headers"])
+	for _, name := range sortedKeys(headers) {
+		c.parameter(headers[name], pointer(p, "headers", name))
+	}
+	bodies := object(components["requestBodies"])
+	for _, name := range sortedKeys(bodies) {
+		c.content(object(bodies[name])["content"], pointer(p, "requestBodies", name, "content"))
+	}
+	responses := object(components["responses"])
+	for _, name := range sortedKeys(responses) {
+		c.response(responses[name], pointer(p, "responses", name))
+	}
+	callbacks := object(components["callbacks"])
+	for _, name := range sortedKeys(callbacks) {
+		c.callback(callbacks[name], pointer(p, "callbacks", name))
+	}
+}
+
+// inlinePathItem returns the path item of components.pathItems if item refers to it, which OpenAPI 3.0 does not have.
+func inlinePathItem(item interface{}, pathItems map[string]interface{}) interface{} {
+	ref, _ := object(item)["$ref"].(string)
+	const prefix = "#/components/pathItems/"
+	if !strings.HasPrefix(ref, prefix) {
+		return item
+	}
+	if resolved, ok := pathItems[unescape(strings.TrimPrefix(ref, prefix))]; ok {
+		return copyValue(resolved)
+	}
+
+	return item
+}
+
+// methods is the HTTP methods of the path item operations.
+var methods = []string{"get", "put", "post", "delete", "options", "head", "patch", "trace"}
+
+// pathItem converts the operations of the path item, and returns item.
+func (c *converter) pathItem(item interface{}, p string) interface{} {
+	obj := object(item)
+	if obj == nil {
+		return item
+	}
+
+	c.parameters(obj["parameters"], pointer(p, "parameters"))
+	for _, method := range methods {
+		op := object(obj[method])
+		if op == nil {
+			continue
+		}
+		opPointer := pointer(p, method)
+		c.parameters(op["parameters"], pointer(opPointer, "parameters"))
+		if body := object(op["requestBody"]); body != nil {
+			c.content(body["content"], pointer(opPointer, "requestBody", "content"))
+		}
+		responses := object(op["responses"])
+		for _, code := range sortedKeys(responses) {
+			c.response(responses[code], pointer(opPointer, "responses", code))
+		}
+		callbacks := object(op["callbacks"])
+		for _, name := range sortedKeys(callbacks) {
+			c.callback(callbacks[name], pointer(opPointer, "callbacks", name))
+		}
+	}
+
+	return item
+}
+
+// callback converts the path items of the callback.
+func (c *converter) callback(callback interface{}, p string) {
+	obj := object(callback)
+	for _, expr := range sortedKeys(obj) {
+		c.pathItem(obj[expr], pointer(p, expr))
+	}
+}
+
+// parameters converts the list of the parameters.
+func (c *converter) parameters(params interface{}, p string) {
+	list, _ := params.([]interface{})
+	for i, param := range list {
+		c.parameter(param, pointer(p, strconv.Itoa(i)))
+	}
+}
+
+// parameter converts the schema of the parameter or the header.
+func (c *converter) parameter(param interface{}, p string) {
+	obj := object(param)
+	if obj == nil {
+		return
+	}
+	c.schema(obj["schema"], pointer(p, "schema"))
+	c.content(obj["content"], pointer(p, "content"))
+}
+
+// response converts the contents and the headers of the response.
+func (c *converter) response(resp interface{}, p string) {
+	obj := object(resp)
+	if obj == nil {
+		return
+	}
+	c.content(obj["content"], pointer(p, "content"))
+	headers := object(obj["headers"])
+	for _, name := range sortedKeys(headers) {
+		c.parameter(headers[name], pointer(p, "headers", name))
+	}
+}
+
+// content converts the schemas of the media types.
+func (c *converter) content(content interface{}, p string) {
+	obj := object(content)
+	for _, mediaType := range sortedKeys(obj) {
+		c.schema(object(obj[mediaType])["schema"], pointer(p, mediaType, "schema"))
+	}
+}
+
+// pointer returns the JSON pointer of the tokens under p.
+func pointer(p string, tokens ...string) string {
+	return p + srcmap.Pointer(tokens...)
+}
+
+// unescape unescapes the reference token of the JSON pointer.
+func unescape(token string) string {
+	return strings.NewReplacer("~1", "/", "~0", "~").Replace(token)
+}
+
+// object returns v as the JSON object, or nil if not an object.
+func object(v interface{}) map[string]interface{} {
+	obj, _ := v.(map[string]interface{})
+	return obj
+}
+
+// sortedKeys returns the sorted keys of the JSON object.
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// copyValue returns the deep copy of the decoded JSON value.
+func copyValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		obj := make(map[string]interface{}, len(v))
+		for key, val := range v {
+			obj[key] = copyValue(val)
+		}
+		return obj
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, val := range v {
+			list[i] = copyValue(val)
+		}
+		return list
+	default:
+		return v
+	}
+}
@@ -0,0 +1,146 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openapi31
+
+import (
+	"reflect"
+	"testing"
+
+	json "github.com/goccy/go-json"
+)
+
+func TestIsVersion(t *testing.T) {
+	tests := map[string]bool{
+		"3.1.0":  true,
+		"3.1.12": true,
+		"3.0.3":  false,
+		"3.1":    false,
+		"3.10.0": false,
+		"2.0":    false,
+	}
+	for version, want := range tests {
+		if got := IsVersion(version); got != want {
+			t.Errorf("IsVersion(%q) = %t, want %t", version, got, want)
+		}
+	}
+}
+
+// schemaDoc returns the OpenAPI document which has the component schemas.
+func schemaDoc(version, schemas string) string {
+	return `{"openapi":"` + version + `","info":{"title":"Test","version":"1.0.0"},"paths":{},"components":{"schemas":` + schemas + `}}`
+}
+
+func TestConvert(t *testing.T) {
+	tests := map[string]struct {
+		data     string
+		want     string
+		warnings []string // pointer and message of the warnings
+	}{
+		"Info": {
+			data: `openapi: 3.1.0
+jsonSchemaDialect: https://spec.openapis.org/oas/3.1/dialect/base
+info:
+  title: Test
+  summary: The test API
+  version: 1.0.0
+  license:
+    name: MIT
+    identifier: MIT
+`,
+			want: `{"openapi":"3.0.3","info":{"title":"Test","version":"1.0.0","license":{"name":"MIT"}},"paths":{}}`,
+		},
+		"NullableType": {
+			data: schemaDoc("3.1.0", `{"Name":{"type":["string","null"]}}`),
+			want: schemaDoc(Version, `{"Name":{"type":"string","nullable":true}}`),
+		},
+		"MultipleTypes": {
+			data: schemaDoc("3.1.0", `{"ID":{"type":["string","integer"]}}`),
+			want: schemaDoc(Version, `{"ID":{"anyOf":[{"type":"string"},{"type":"integer"}]}}`),
+		},
+		"Const": {
+			data: schemaDoc("3.1.0", `{"Kind":{"const":"pet"},"Answer":{"const":42}}`),
+			want: schemaDoc(Version, `{"Kind":{"type":"string","enum":["pet"]},"Answer":{"type":"integer","enum":[42]}}`),
+		},
+		"ExclusiveBounds": {
+			data: schemaDoc("3.1.0", `{"Age":{"type":"integer","exclusiveMinimum":0,"maximum":10,"exclusiveMaximum":20}}`),
+			want: schemaDoc(Version, `{"Age":{"type":"integer","minimum":0,"exclusiveMinimum":true,"maximum":10}}`),
+		},
+		"Examples": {
+			data: schemaDoc("3.1.0", `{"Name":{"type":"string","examples":["a","b"]}}`),
+			want: schemaDoc(Version, `{"Name":{"type":"string","example":"a"}}`),
+		},
+		"Defs": {
+			data: schemaDoc("3.1.0", `{"Pet":{"type":"object","properties":{"tag":{"$ref":"#/components/schemas/Pet/$defs/Tag"}},"$defs":{"Tag":{"type":"string"}}}}`),
+			want: schemaDoc(Version, `{"Pet":{"type":"object","properties":{"tag":{"$ref":"#/components/schemas/Tag"}}},"Tag":{"type":"string"}}`),
+		},
+		"DefsConflict": {
+			data: schemaDoc("3.1.0", `{"Tag":{"type":"integer"},"Pet":{"properties":{"tag":{"$ref":"#/components/schemas/Pet/$defs/Tag"}},"$defs":{"Tag":{"type":"string"}}}}`),
+			want: schemaDoc(Version, `{"Tag":{"type":"integer"},"Pet":{"properties":{"tag":{"$ref":"#/components/schemas/Pet_Tag"}}},"Pet_Tag":{"type":"string"}}`),
+		},
+		"RefSiblings": {
+			data: schemaDoc("3.1.0", `{"Pet":{"type":"object"},"Owner":{"properties":{"pet":{"$ref":"#/components/schemas/Pet","description":"The pet","type":["object","null"],"x-go-name":"Pet"}}}}`),
+			want: schemaDoc(Version, `{"Pet":{"type":"object"},"Owner":{"properties":{"pet":{"$ref":"#/components/schemas/Pet","x-go-name":"Pet"}}}}`),
+			warnings: []string{
+				"#/components/schemas/Owner/properties/pet/type: type next to $ref can not be represented in OpenAPI 3.0, ignored",
+			},
+		},
+		"RefSiblingDefs": {
+			data: schemaDoc("3.1.0", `{"Pet":{"$ref":"#/components/schemas/Pet/$defs/Base","$defs":{"Base":{"type":"object"}}}}`),
+			want: schemaDoc(Version, `{"Pet":{"$ref":"#/components/schemas/Base"},"Base":{"type":"object"}}`),
+		},
+		"Unsupported": {
+			data: schemaDoc("3.1.0", `{"Pet":{"type":"object","$id":"pet","if":{"required":["a"]},"then":{"required":["b"]},"prefixItems":[{"type":"string"}]}}`),
+			want: schemaDoc(Version, `{"Pet":{"type":"object","items":{}}}`),
+			warnings: []string{
+				"#/components/schemas/Pet/if: if can not be represented in OpenAPI 3.0, ignored",
+				"#/components/schemas/Pet/then: then can not be represented in OpenAPI 3.0, ignored",
+				"#/components/schemas/Pet/prefixItems: prefixItems can not be represented in OpenAPI 3.0, the items are any type",
+			},
+		},
+		"Webhooks": {
+			data: `{"openapi":"3.1.0","info":{"title":"Test","version":"1.0.0"},"webhooks":{"newPet":{"post":{"requestBody":{"content":{"application/json":{"schema":{"type":["object","null"]}}}},"responses":{"200":{"description":"OK"}}}}}}`,
+			want: `{"openapi":"3.0.3","info":{"title":"Test","version":"1.0.0"},"paths":{},"x-webhooks":{"newPet":{"post":{"requestBody":{"content":{"application/json":{"schema":{"type":"object","nullable":true}}}},"responses":{"200":{"description":"OK"}}}}}}`,
+		},
+		"PathItems": {
+			data: `{"openapi":"3.1.0","info":{"title":"Test","version":"1.0.0"},"paths":{"/pets":{"$ref":"#/components/pathItems/Pets"}},"components":{"pathItems":{"Pets":{"get":{"responses":{"200":{"description":"OK"}}}}}}}`,
+			want: `{"openapi":"3.0.3","info":{"title":"Test","version":"1.0.0"},"paths":{"/pets":{"get":{"responses":{"200":{"description":"OK"}}}}},"components":{}}`,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+
+			out, diags, err := Convert([]byte(tt.data))
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			var got, want interface{}
+			if err := json.Unmarshal(out, &got); err != nil {
+				t.Fatal(err)
+			}
+			if err := json.Unmarshal([]byte(tt.want), &want); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, want) {
+				t.Errorf("Convert() =\n%s\nwant:\n%s", out, tt.want)
+			}
+
+			var warnings []string
+			for _, d := range diags {
+				warnings = append(warnings, d.Pointer+": "+d.Msg)
+			}
+			if !reflect.DeepEqual(warnings, tt.warnings) {
+				t.Errorf("warnings = %q, want %q", warnings, tt.warnings)
+			}
+		})
+	}
+}
+
+func TestConvertInvalid(t *testing.T) {
+	if _, _, err := Convert([]byte("openapi: [3.1.0")); err == nil {
+		t.Fatal("Convert() of the invalid YAML returns no error")
+	}
+}
@@ -0,0 +1,333 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package openapi31
+
+import (
+	"strconv"
+	"strings"
+
+	json "github.com/goccy/go-json"
+)
+
+// ignoredKeywords is the JSON Schema keywords which are removed silently, those do not affect the generated code.
+var ignoredKeywords = []string{"$schema", "$id", "$anchor", "$comment", "$vocabulary", "$dynamicAnchor"}
+
+// unsupportedKeywords is the JSON Schema keywords which can not be represented in OpenAPI 3.0, those are removed with
+// the warning.
+var unsupportedKeywords = []string{
+	"$dynamicRef",
+	"if", "then", "else",
+	"dependentRequired", "dependentSchemas",
+	"patternProperties", "propertyNames",
+	"contains", "minContains", "maxContains",
+	"unevaluatedItems",
+}
+
+// schemaListKeywords is the keywords which have the list of the subschemas.
+var schemaListKeywords = []string{"allOf", "anyOf", "oneOf"}
+
+// schema converts the JSON Schema 2020-12 schema to the OpenAPI 3.0 schema in place.
+func (c *converter) schema(v interface{}, p string) {
+	s := object(v)
+	if s == nil {
+		return
+	}
+	c.moveSchemaDefs(s, p)
+	if _, ok := s["$ref"]; ok {
+		c.refSiblings(s, p)
+		return
+	}
+
+	for _, keyword := range ignoredKeywords {
+		delete(s, keyword)
+	}
+	for _, keyword := range unsupportedKeywords {
+		if _, ok := s[keyword]; ok {
+			c.warnf(pointer(p, keyword), "%s can not be represented in OpenAPI 3.0, ignored", keyword)
+			delete(s, keyword)
+		}
+	}
+
+	c.schemaType(s)
+	c.constValue(s)
+	exclusiveBound(s, "exclusiveMinimum", "minimum", 1)
+	exclusiveBound(s, "exclusiveMaximum", "maximum", -1)
+	if examples, ok := s["examples"].([]interface{}); ok {
+		if _, ok := s["example"]; !ok && len(examples) > 0 {
+			s["example"] = examples[0]
+		}
+		delete(s, "examples")
+	}
+	if s["contentEncoding"] == "base64" {
+		if _, ok := s["format"]; !ok {
+			s["format"] = "byte"
+		}
+	}
+	delete(s, "contentEncoding")
+	delete(s, "contentMediaType")
+	delete(s, "contentSchema")
+
+	if _, ok := s["prefixItems"]; ok {
+		c.warnf(pointer(p, "prefixItems"), "prefixItems can not be represented in OpenAPI 3.0, the items are any type")
+		s["items"] = map[string]interface{}{}
+		delete(s, "prefixItems")
+	}
+	if _, ok := s["unevaluatedProperties"]; ok {
+		if c.unevaluatedProperties(s) {
+			s["additionalProperties"] = s["unevaluatedProperties"]
+		} else {
+			c.warnf(pointer(p, "unevaluatedProperties"), "unevaluatedProperties of the composed schema can not be represented in OpenAPI 3.0, ignored")
+		}
+		delete(s, "unevaluatedProperties")
+	}
+	if items, ok := s["items"].(bool); ok {
+		// the boolean schemas are not allowed in OpenAPI 3.0
+		if !items {
+			s["maxItems"] = 0
+		}
+		s["items"] = map[string]interface{}{}
+	}
+
+	props := object(s["properties"])
+	for _, name := range sortedKeys(props) {
+		c.schema(props[name], pointer(p, "properties", name))
+	}
+	c.schema(s["additionalProperties"], pointer(p, "additionalProperties"))
+	c.schema(s["items"], pointer(p, "items"))
+	c.schema(s["not"], pointer(p, "not"))
+	for _, keyword := range schemaListKeywords {
+		list, _ := s[keyword].([]interface{})
+		for i, sub := range list {
+			c.schema(sub, pointer(p, keyword, strconv.Itoa(i)))
+		}
+	}
+}
+
+// moveSchemaDefs appends the $defs of the schema to c.defs, which are moved to the component schemas.
+func (c *converter) moveSchemaDefs(s map[string]interface{}, p string) {
+	defs := object(s["$defs"])
+	if defs == nil {
+		return
+	}
+	for _, name := range sortedKeys(defs) {
+		c.defs = append(c.defs, &def{name: name, schema: defs[name], pointer: pointer(p, "$defs", name)})
+	}
+	delete(s, "$defs")
+}
+
+// annotationKeywords is the keywords which do not affect the generated code, those are removed silently from the
+// siblings of $ref.
+var annotationKeywords = map[string]bool{
+	"title": true, "summary": true, "description": true, "default": true, "example": true, "examples": true,
+	"deprecated": true, "readOnly": true, "writeOnly": true, "externalDocs": true, "xml": true,
+}
+
+// refSiblings removes the sibling keywords of $ref, which JSON Schema 2020-12 applies with the referenced schema but
+// OpenAPI 3.0 ignores. The keywords which constrain the schema, such as the type arrays and const, are reported as the
+// warnings. The extensions are kept.
+func (c *converter) refSiblings(s map[string]interface{}, p string) {
+	for _, keyword := range sortedKeys(s) {
+		switch {
+		case keyword == "$ref" || strings.HasPrefix(keyword, "x-"):
+			continue
+		case annotationKeywords[keyword] || contains(ignoredKeywords, keyword):
+		default:
+			c.warnf(pointer(p, keyword), "%s next to $ref can not be represented in OpenAPI 3.0, ignored", keyword)
+		}
+		delete(s, keyword)
+	}
+}
+
+// contains reports whether list contains s.
+func contains(list []string, s string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+	return false
+}
+
+// schemaType converts the type array, which may have "null", to the nullable schema of the type. The multiple types
+// are the anyOf of the types.
+func (c *converter) schemaType(s map[string]interface{}) {
+	var types []string
+	switch typ := s["type"].(type) {
+	case string:
+		types = []string{typ}
+	case []interface{}:
+		for _, t := range typ {
+			if t, ok := t.(string); ok {
+				types = append(types, t)
+			}
+		}
+	default:
+		return
+	}
+
+	var nonNull []string
+	for _, t := range types {
+		if t == "null" {
+			s["nullable"] = true
+			continue
+		}
+		nonNull = append(nonNull, t)
+	}
+
+	switch len(nonNull) {
+	case 0:
+		delete(s, "type")
+	case 1:
+		s["type"] = nonNull[0]
+	default:
+		delete(s, "type")
+		anyOf := make([]interface{}, len(nonNull))
+		for i, t := range nonNull {
+			anyOf[i] = map[string]interface{}{"type": t}
+		}
+		if _, ok := s["anyOf"]; ok {
+			s["allOf"] = append(list(s["allOf"]), map[string]interface{}{"anyOf": anyOf})
+		} else {
+			s["anyOf"] = anyOf
+		}
+	}
+}
+
+// constValue converts const to the enum of the single value. The type is the type of the value if not given.
+func (c *converter) constValue(s map[string]interface{}) {
+	v, ok := s["const"]
+	if !ok {
+		return
+	}
+	if _, ok := s["enum"]; !ok {
+		s["enum"] = []interface{}{v}
+	}
+	delete(s, "const")
+
+	if _, ok := s["type"]; ok {
+		return
+	}
+	switch v := v.(type) {
+	case string:
+		s["type"] = "string"
+	case bool:
+		s["type"] = "boolean"
+	case json.Number:
+		s["type"] = "number"
+		if _, err := v.Int64(); err == nil {
+			s["type"] = "integer"
+		}
+	case nil:
+		s["nullable"] = true
+	}
+}
+
+// exclusiveBound converts the numeric exclusive bound to the bound and the boolean exclusive bound.
+//
+// sign is 1 for the minimum and -1 for the maximum, the stricter bound is kept if both are given.
+func exclusiveBound(s map[string]interface{}, exclusive, bound string, sign float64) {
+	v, ok := s[exclusive].(json.Number)
+	if !ok {
+		return
+	}
+	delete(s, exclusive)
+
+	if b, ok := s[bound].(json.Number); ok {
+		bf, _ := b.Float64()
+		vf, _ := v.Float64()
+		if (bf-vf)*sign > 0 {
+			return // the inclusive bound is stricter
+		}
+	}
+	s[bound] = v
+	s[exclusive] = true
+}
+
+// unevaluatedProperties reports whether unevaluatedProperties of s is the same as additionalProperties, which is true
+// if s is not composed and has no additionalProperties.
+func (c *converter) unevaluatedProperties(s map[string]interface{}) bool {
+	if _, ok := s["additionalProperties"]; ok {
+		return false
+	}
+	for _, keyword := range schemaListKeywords {
+		if _, ok := s[keyword]; ok {
+			return false
+		}
+	}
+
+	return true
+}
+
+// moveDefs moves the $defs to the component schemas, which are named by the $defs names. The name is prefixed by the
+// names of the parent schemas if the component schema already exists.
+func (c *converter) moveDefs() {
+	if len(c.defs) == 0 {
+		return
+	}
+
+	components := object(c.doc["components"])
+	if components == nil {
+		components = make(map[string]interface{})
+		c.doc["components"] = components
+	}
+	schemas := object(components["schemas"])
+	if schemas == nil {
+		schemas = make(map[string]interface{})
+		components["schemas"] = schemas
+	}
+
+	// the nested $defs are appended to c.defs while converting
+	for i := 0; i < len(c.defs); i++ {
+		d := c.defs[i]
+		name := d.name
+		if _, ok := schemas[name]; ok {
+			name = defName(d.pointer)
+			for n := 2; schemas[name] != nil; n++ {
+				name = defName(d.pointer) + strconv.Itoa(n)
+			}
+		}
+		schemas[name] = d.schema
+		c.refs[d.pointer] = pointer("#", "components", "schemas", name)
+		c.schema(d.schema, d.pointer)
+	}
+}
+
+// defName returns the component schema name of the $defs pointer, which joins the names of the schema and its parents
+// such as "Pet_Tag" of "#/components/schemas/Pet/$defs/Tag".
+func defName(p string) string {
+	var names []string
+	tokens := strings.Split(p, "/")
+	for i := 1; i < len(tokens); i++ {
+		if tokens[i-1] == "schemas" || tokens[i-1] == "$defs" {
+			names = append(names, unescape(tokens[i]))
+		}
+	}
+
+	return strings.Join(names, "_")
+}
+
+// rewriteRefs rewrites the $ref to the $defs in v to the $ref to the moved component schemas.
+func (c *converter) rewriteRefs(v interface{}) {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			if moved, ok := c.refs[ref]; ok {
+				v["$ref"] = moved
+			}
+		}
+		for _, val := range v {
+			c.rewriteRefs(val)
+		}
+	case []interface{}:
+		for _, val := range v {
+			c.rewriteRefs(val)
+		}
+	}
+}
+
+// list returns v as the JSON array, or nil if not an array.
+func list(v interface{}) []interface{} {
+	l, _ := v.([]interface{})
+	return l
+}
@@ -0,0 +1,38 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package schemaformat defines the common string formats which are not defined by kin-openapi by default.
+//
+// The formats are defined by Define explicitly, importing this package has no side effects.
+package schemaformat
+
+import (
+	"sync"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// uuidPattern is the regexp pattern of the RFC 4122 UUID.
+const uuidPattern = `^[0-9a-fA-F]{8}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{4}-[0-9a-fA-F]{12}$`
+
+var defineOnce sync.Once
+
+// Define defines the uuid, ipv4 and ipv6 string formats which are not defined yet, the formats defined by the
+// others are kept.
+//
+// kin-openapi has no string formats per loader or validator, the formats are defined in the process wide
+// openapi3.SchemaStringFormats. Define must be called before the validations, the table is not safe for the
+// concurrent use.
+func Define() {
+	defineOnce.Do(func() {
+		if _, ok := openapi3.SchemaStringFormats["uuid"]; !ok {
+			openapi3.DefineStringFormat("uuid", uuidPattern)
+		}
+		if _, ok := openapi3.SchemaStringFormats["ipv4"]; !ok {
+			openapi3.DefineIPv4Format()
+		}
+		if _, ok := openapi3.SchemaStringFormats["ipv6"]; !ok {
+			openapi3.DefineIPv6Format()
+		}
+	})
+}
@@ -0,0 +1,108 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package srcmap maps the JSON pointers of a JSON or YAML document to the source positions.
+package srcmap
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Position represents a position in the source document.
+type Position struct {
+	Line   int // 1-based line number
+	Column int // 1-based column number
+}
+
+// String returns a string representation of the Position.
+func (p Position) String() string {
+	if p.Line == 0 {
+		return ""
+	}
+	return fmt.Sprintf("%d:%d", p.Line, p.Column)
+}
+
+// IsValid reports whether the position is valid.
+func (p Position) IsValid() bool {
+	return p.Line > 0
+}
+
+// Map is the map of the RFC 6901 JSON pointer to the source position.
+type Map map[string]Position
+
+// Parse parses the JSON or YAML data and returns the Map of data.
+func Parse(data []byte) (Map, error) {
+	var root yaml.Node
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, err
+	}
+
+	m := make(Map)
+	if len(root.Content) > 0 {
+		m.walk(root.Content[0], "")
+	}
+
+	return m, nil
+}
+
+func (m Map) walk(node *yaml.Node, pointer string) {
+	if _, ok := m[pointer]; !ok {
+		m[pointer] = Position{Line: node.Line, Column: node.Column}
+	}
+
+	switch node.Kind {
+	case yaml.MappingNode:
+		for i := 0; i+1 < len(node.Content); i += 2 {
+			key, val := node.Content[i], node.Content[i+1]
+			p := pointer + "/" + Escape(key.Value)
+			// points to the key rather than the value, which is more natural for the reader
+			m[p] = Position{Line: key.Line, Column: key.Column}
+			m.walk(val, p)
+		}
+
+	case yaml.SequenceNode:
+		for i, val := range node.Content {
+			m.walk(val, pointer+"/"+strconv.Itoa(i))
+		}
+
+	case yaml.AliasNode:
+		if node.Alias != nil {
+			m.walk(node.Alias, pointer)
+		}
+	}
+}
+
+// Lookup returns the position of pointer.
+//
+// If pointer does not exist in the document, returns the position of the nearest existing parent.
+func (m Map) Lookup(pointer string) Position {
+	for {
+		if pos, ok := m[pointer]; ok {
+			return pos
+		}
+		idx := strings.LastIndexByte(pointer, '/')
+		if idx < 0 {
+			return Position{}
+		}
+		pointer = pointer[:idx]
+	}
+}
+
+// Escape escapes the reference token of the JSON pointer.
+func Escape(token string) string {
+	return strings.NewReplacer("~", "~0", "/", "~1").Replace(token)
+}
+
+// Pointer returns the JSON pointer of the tokens.
+func Pointer(tokens ...string) string {
+	var sb strings.Builder
+	for _, tok := range tokens {
+		sb.WriteByte('/')
+		sb.WriteString(Escape(tok))
+	}
+	return sb.String()
+}
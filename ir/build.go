@@ -0,0 +1,409 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ir
+
+import (
+	stdjson "encoding/json"
+	"errors"
+	"fmt"
+	pathpkg "path"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	json "github.com/goccy/go-json"
+
+	"github.com/zchee/go-openapi-tools/internal/openapi31"
+)
+
+// DefaultService is the service name of the operations which are not assigned to any service.
+const DefaultService = "default"
+
+// Option configures Build.
+type Option func(*builder)
+
+// WithServiceName sets the function which returns the service name of the tag name.
+//
+// The default is the tag name as is.
+func WithServiceName(fn func(tag string) string) Option {
+	return func(b *builder) {
+		b.serviceName = fn
+	}
+}
+
+// WithOperationServices sets the function which returns the service names which the operation is assigned to.
+//
+// The default is the service of the first tag, or DefaultService if the operation has no tags.
+func WithOperationServices(fn func(op *Operation) []string) Option {
+	return func(b *builder) {
+		b.operationServices = fn
+	}
+}
+
+// builder builds the API from the OpenAPI document.
+type builder struct {
+	doc               *openapi3.T
+	serviceName       func(tag string) string
+	operationServices func(op *Operation) []string
+
+	models map[string]*Type // component schema name to the type
+	built  map[string]bool  // component schema names which are built
+}
+
+// Build builds the API from the OpenAPI document.
+//
+// The $ref to the components of doc are resolved by the component names, the external references which are not found
+// in the components are kept as the Ref types which have no Elem.
+func Build(doc *openapi3.T, opts ...Option) (*API, error) {
+	if doc == nil {
+		return nil, errors.New("no OpenAPI document")
+	}
+
+	b := &builder{
+		doc:         doc,
+		serviceName: func(tag string) string { return tag },
+		models:      make(map[string]*Type),
+		built:       make(map[string]bool),
+	}
+	b.operationServices = b.defaultOperationServices
+	for _, o := range opts {
+		o(b)
+	}
+
+	api := &API{}
+	if doc.Info != nil {
+		api.Title = doc.Info.Title
+		api.Version = doc.Info.Version
+		api.Description = doc.Info.Description
+	}
+	for _, server := range doc.Servers {
+		if server != nil {
+			api.Servers = append(api.Servers, server.URL)
+		}
+	}
+
+	// allocates the component schemas first, those are referenced by the other schemas
+	names := sortedKeys(doc.Components.Schemas)
+	for _, name := range names {
+		b.models[name] = &Type{}
+	}
+	for _, name := range names {
+		api.Models = append(api.Models, &Model{Name: name, Type: b.model(name)})
+	}
+
+	for _, path := range sortedKeys(doc.Paths) {
+		item := doc.Paths[path]
+		for _, method := range sortedKeys(item.Operations()) {
+			api.Operations = append(api.Operations, b.operation(path, method, item, item.GetOperation(method)))
+		}
+	}
+	api.Services = b.services(api.Operations)
+
+	webhooks, err := b.webhooks()
+	if err != nil {
+		return nil, err
+	}
+	for _, name := range sortedKeys(webhooks) {
+		item := webhooks[name]
+		if item == nil {
+			continue
+		}
+		for _, method := range sortedKeys(item.Operations()) {
+			api.Webhooks = append(api.Webhooks, &Webhook{
+				Name:      name,
+				Operation: b.operation("", method, item, item.GetOperation(method)),
+			})
+		}
+	}
+	for _, op := range api.Operations {
+		api.Webhooks = append(api.Webhooks, b.callbacks(op)...)
+	}
+
+	return api, nil
+}
+
+// webhooks returns the webhooks of the OpenAPI 3.1 schema, which are converted to the openapi31.WebhooksExtension.
+func (b *builder) webhooks() (map[string]*openapi3.PathItem, error) {
+	ext, ok := b.doc.Extensions[openapi31.WebhooksExtension]
+	if !ok {
+		return nil, nil
+	}
+
+	// the extension is the encoding/json raw JSON if decoded by kin-openapi, or any value if set by the caller
+	data, ok := ext.(stdjson.RawMessage)
+	if !ok {
+		var err error
+		if data, err = json.Marshal(ext); err != nil {
+			return nil, fmt.Errorf("failed to encode %s: %w", openapi31.WebhooksExtension, err)
+		}
+	}
+	var webhooks map[string]*openapi3.PathItem
+	if err := json.Unmarshal(data, &webhooks); err != nil {
+		return nil, fmt.Errorf("failed to decode %s: %w", openapi31.WebhooksExtension, err)
+	}
+
+	return webhooks, nil
+}
+
+// callbacks returns the callbacks of the operation sorted by the name, runtime expression and HTTP method.
+func (b *builder) callbacks(op *Operation) []*Webhook {
+	item := b.doc.Paths[op.Path]
+	if item == nil {
+		return nil
+	}
+	src := item.GetOperation(op.Method)
+	if src == nil {
+		return nil
+	}
+
+	var webhooks []*Webhook
+	for _, name := range sortedKeys(src.Callbacks) {
+		callback := b.callback(src.Callbacks[name])
+		for _, expr := range sortedKeys(callback) {
+			cbItem := callback[expr]
+			if cbItem == nil {
+				continue
+			}
+			for _, method := range sortedKeys(cbItem.Operations()) {
+				webhooks = append(webhooks, &Webhook{
+					Name:       name,
+					Expression: expr,
+					Callback:   op,
+					Operation:  b.operation(expr, method, cbItem, cbItem.GetOperation(method)),
+				})
+			}
+		}
+	}
+
+	return webhooks
+}
+
+// sortedKeys returns the sorted keys of the map m which has the string keys.
+func sortedKeys(m interface{}) []string {
+	v := reflect.ValueOf(m)
+	if v.Kind() != reflect.Map || v.Type().Key().Kind() != reflect.String {
+		return nil
+	}
+
+	keys := make([]string, 0, v.Len())
+	iter := v.MapRange()
+	for iter.Next() {
+		keys = append(keys, iter.Key().String())
+	}
+	sort.Strings(keys)
+
+	return keys
+}
+
+// defaultOperationServices returns the service of the first tag of op.
+func (b *builder) defaultOperationServices(op *Operation) []string {
+	if len(op.Tags) == 0 {
+		return []string{DefaultService}
+	}
+
+	return []string{b.serviceName(op.Tags[0])}
+}
+
+// services groups the operations by the services, sorted by the service name.
+//
+// The services of the declared tags are kept even if those have no operations, and the DefaultService is returned if
+// there are no services.
+func (b *builder) services(ops []*Operation) []*Service {
+	serviceMap := make(map[string]*Service)
+	var services []*Service
+	service := func(name string) *Service {
+		svc, ok := serviceMap[name]
+		if !ok {
+			svc = &Service{Name: name}
+			serviceMap[name] = svc
+			services = append(services, svc)
+		}
+		return svc
+	}
+
+	for _, tag := range b.doc.Tags {
+		if tag == nil {
+			continue
+		}
+		svc := service(b.serviceName(tag.Name))
+		svc.Tags = append(svc.Tags, tag.Name)
+	}
+	for _, op := range ops {
+		for _, name := range b.operationServices(op) {
+			svc := service(name)
+			if n := len(svc.Operations); n == 0 || svc.Operations[n-1] != op {
+				svc.Operations = append(svc.Operations, op)
+			}
+		}
+	}
+	if len(services) == 0 {
+		service(DefaultService)
+	}
+
+	sort.SliceStable(services, func(i, j int) bool { return services[i].Name < services[j].Name })
+
+	return services
+}
+
+// operation builds the operation of the HTTP method of the path item.
+func (b *builder) operation(path, method string, item *openapi3.PathItem, op *openapi3.Operation) *Operation {
+	o := &Operation{
+		ID:          op.OperationID,
+		Method:      strings.ToUpper(method),
+		Path:        path,
+		Summary:     op.Summary,
+		Description: op.Description,
+		Deprecated:  op.Deprecated,
+		Tags:        op.Tags,
+		Extensions:  op.Extensions,
+	}
+
+	// the operation parameters override the path item parameters of the same location and name
+	declared := make(map[string]bool)
+	for _, ref := range op.Parameters {
+		if p := b.param(ref); p != nil {
+			declared[p.In+" "+p.Name] = true
+			o.Params = append(o.Params, p)
+		}
+	}
+	for _, ref := range item.Parameters {
+		if p := b.param(ref); p != nil && !declared[p.In+" "+p.Name] {
+			o.Params = append(o.Params, p)
+		}
+	}
+
+	if body := b.requestBody(op.RequestBody); body != nil {
+		o.Body = &Body{
+			Description: body.Description,
+			Required:    body.Required,
+			Content:     b.content(body.Content),
+		}
+	}
+
+	for _, code := range sortedKeys(op.Responses) {
+		resp := &Response{Code: code}
+		if val := b.response(op.Responses[code]); val != nil {
+			if val.Description != nil {
+				resp.Description = *val.Description
+			}
+			resp.Content = b.content(val.Content)
+			for _, name := range sortedKeys(val.Headers) {
+				if hdr := b.header(val.Headers[name]); hdr != nil {
+					resp.Headers = append(resp.Headers, &Header{
+						Name:        name,
+						Description: hdr.Description,
+						Required:    hdr.Required,
+						Type:        b.schemaType(hdr.Schema),
+					})
+				}
+			}
+		}
+		o.Responses = append(o.Responses, resp)
+	}
+
+	return o
+}
+
+// callback returns the callback of ref, or nil if not resolved.
+func (b *builder) callback(ref *openapi3.CallbackRef) openapi3.Callback {
+	if ref == nil {
+		return nil
+	}
+	if ref.Value == nil && ref.Ref != "" {
+		if resolved := b.doc.Components.Callbacks[pathpkg.Base(ref.Ref)]; resolved != nil && resolved.Value != nil {
+			return *resolved.Value
+		}
+	}
+	if ref.Value == nil {
+		return nil
+	}
+
+	return *ref.Value
+}
+
+// param returns the parameter of ref, or nil if not resolved.
+func (b *builder) param(ref *openapi3.ParameterRef) *Param {
+	if ref == nil {
+		return nil
+	}
+	val := ref.Value
+	if val == nil && ref.Ref != "" {
+		if resolved := b.doc.Components.Parameters[pathpkg.Base(ref.Ref)]; resolved != nil {
+			val = resolved.Value
+		}
+	}
+	if val == nil {
+		return nil
+	}
+
+	// the default style of the query and cookie parameters is form, which explodes by default
+	explode := val.Style == openapi3.SerializationForm || (val.Style == "" && (val.In == InQuery || val.In == InCookie))
+	if val.Explode != nil {
+		explode = *val.Explode
+	}
+
+	return &Param{
+		Name:        val.Name,
+		In:          val.In,
+		Description: val.Description,
+		Required:    val.Required,
+		Explode:     explode,
+		Type:        b.schemaType(val.Schema),
+	}
+}
+
+// requestBody returns the request body of ref, or nil if none.
+func (b *builder) requestBody(ref *openapi3.RequestBodyRef) *openapi3.RequestBody {
+	if ref == nil {
+		return nil
+	}
+	if ref.Value == nil && ref.Ref != "" {
+		if resolved := b.doc.Components.RequestBodies[pathpkg.Base(ref.Ref)]; resolved != nil {
+			return resolved.Value
+		}
+	}
+
+	return ref.Value
+}
+
+// response returns the response of ref, or nil if not resolved.
+func (b *builder) response(ref *openapi3.ResponseRef) *openapi3.Response {
+	if ref == nil {
+		return nil
+	}
+	if ref.Value == nil && ref.Ref != "" {
+		if resolved := b.doc.Components.Responses[pathpkg.Base(ref.Ref)]; resolved != nil {
+			return resolved.Value
+		}
+	}
+
+	return ref.Value
+}
+
+// header returns the header of ref, or nil if not resolved.
+func (b *builder) header(ref *openapi3.HeaderRef) *openapi3.Header {
+	if ref == nil {
+		return nil
+	}
+	if ref.Value == nil && ref.Ref != "" {
+		if resolved := b.doc.Components.Headers[pathpkg.Base(ref.Ref)]; resolved != nil {
+			return resolved.Value
+		}
+	}
+
+	return ref.Value
+}
+
+// content returns the contents sorted by the media type.
+func (b *builder) content(content openapi3.Content) []*Media {
+	var media []*Media
+	for _, mediaType := range sortedKeys(content) {
+		if mt := content[mediaType]; mt != nil {
+			media = append(media, &Media{MediaType: mediaType, Type: b.schemaType(mt.Schema)})
+		}
+	}
+
+	return media
+}
@@ -0,0 +1,268 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package ir provides the intermediate representation of the API which is built from the OpenAPI document.
+//
+// The code generators consume the IR instead of the OpenAPI document, so the IR resolves the $ref of the schemas,
+// parameters, request bodies and responses, and groups the operations by the services. The names in the IR are the
+// names in the document, not the Go identifiers, each backend resolves the identifiers by its own rules.
+package ir
+
+// API represents the API described by the OpenAPI document.
+type API struct {
+	Title       string
+	Version     string
+	Description string
+	Servers     []string     // server URLs
+	Services    []*Service   // services sorted by the name
+	Operations  []*Operation // all operations sorted by the path and HTTP method
+	Models      []*Model     // component schemas sorted by the name
+	Webhooks    []*Webhook   // webhooks sorted by the name and HTTP method, followed by the callbacks of Operations
+}
+
+// Model looks up the component schema by the name.
+func (api *API) Model(name string) (*Model, bool) {
+	for _, m := range api.Models {
+		if m.Name == name {
+			return m, true
+		}
+	}
+
+	return nil, false
+}
+
+// Service represents the group of the operations, such as the operations of a tag.
+type Service struct {
+	Name       string
+	Tags       []string     // names of the declared tags which assigned to the service
+	Operations []*Operation // operations sorted by the path and HTTP method
+}
+
+// Operation represents an API operation, which is the HTTP method of the path.
+type Operation struct {
+	ID          string // operation ID, if any
+	Method      string // upper cased HTTP method, such as "GET"
+	Path        string // path template, such as "/pets/{petId}"
+	Summary     string
+	Description string
+	Deprecated  bool
+	Tags        []string
+	Params      []*Param               // the operation parameters merged with the path parameters, in the declared order
+	Body        *Body                  // request body, nil if none
+	Responses   []*Response            // responses sorted by the status code
+	Extensions  map[string]interface{} // specification extensions such as "x-go-service"
+}
+
+// Webhook represents the request which the API sends to the consumer, such as the webhook of the OpenAPI 3.1 schema
+// and the callback of the operation.
+type Webhook struct {
+	Name       string     // webhook name, or callback name of Callback
+	Expression string     // runtime expression of the callback URL such as "{$request.body#/url}", empty if webhook
+	Callback   *Operation // operation which declares the callback, nil if webhook
+	Operation  *Operation // request of the webhook, Path is empty or Expression
+}
+
+// Parameter locations.
+const (
+	InPath   = "path"
+	InQuery  = "query"
+	InHeader = "header"
+	InCookie = "cookie"
+)
+
+// Param represents an operation parameter.
+type Param struct {
+	Name        string
+	In          string // location, one of (path, query, header, cookie)
+	Description string
+	Required    bool
+	Explode     bool // the array values are the separate parameters, the default of the query and cookie parameters
+	Type        *Type
+}
+
+// ParamsIn returns the parameters of op in the location, in the declared order.
+func (op *Operation) ParamsIn(in string) []*Param {
+	var params []*Param
+	for _, p := range op.Params {
+		if p.In == in {
+			params = append(params, p)
+		}
+	}
+
+	return params
+}
+
+// Media represents the content of a media type.
+type Media struct {
+	MediaType string // such as "application/json"
+	Type      *Type  // schema of the content
+}
+
+// MediaJSON is the JSON media type.
+const MediaJSON = "application/json"
+
+// Body represents a request body.
+type Body struct {
+	Description string
+	Required    bool
+	Content     []*Media // contents sorted by the media type
+}
+
+// JSON returns the schema of the JSON content, or nil if none.
+func (b *Body) JSON() *Type {
+	if b == nil {
+		return nil
+	}
+
+	return jsonContent(b.Content)
+}
+
+// Response represents a response of the status code.
+type Response struct {
+	Code        string // status code, such as "200", "2XX" or "default"
+	Description string
+	Content     []*Media  // contents sorted by the media type
+	Headers     []*Header // headers sorted by the name
+}
+
+// JSON returns the schema of the JSON content, or nil if none.
+func (r *Response) JSON() *Type {
+	if r == nil {
+		return nil
+	}
+
+	return jsonContent(r.Content)
+}
+
+// jsonContent returns the schema of the JSON media type in content, if any.
+func jsonContent(content []*Media) *Type {
+	for _, media := range content {
+		if media.MediaType == MediaJSON {
+			return media.Type
+		}
+	}
+
+	return nil
+}
+
+// Header represents a response header.
+type Header struct {
+	Name        string
+	Description string
+	Required    bool
+	Type        *Type
+}
+
+// Model represents the named schema of the components.
+type Model struct {
+	Name string
+	Type *Type
+}
+
+// Kind represents the kind of Type.
+type Kind uint8
+
+const (
+	// Any is the schema which has no type, which accepts any value.
+	Any Kind = iota
+
+	// Primitive is the string, integer, number or boolean schema.
+	Primitive
+
+	// Enum is the primitive schema which has the enumerated values.
+	Enum
+
+	// Struct is the object schema which has the properties.
+	Struct
+
+	// Map is the object schema which has only the additional properties.
+	Map
+
+	// Slice is the array schema.
+	Slice
+
+	// Union is the schema which is one of the variants, such as oneOf and anyOf.
+	Union
+
+	// Ref is the reference to the component schema.
+	Ref
+)
+
+// String returns a string representation of the Kind.
+func (k Kind) String() string {
+	switch k {
+	case Any:
+		return "any"
+	case Primitive:
+		return "primitive"
+	case Enum:
+		return "enum"
+	case Struct:
+		return "struct"
+	case Map:
+		return "map"
+	case Slice:
+		return "slice"
+	case Union:
+		return "union"
+	case Ref:
+		return "ref"
+	default:
+		return "unknown"
+	}
+}
+
+// Type represents the type of a schema.
+type Type struct {
+	Kind        Kind
+	Name        string // component schema name of Ref
+	Ref         string // $ref of Ref, such as "#/components/schemas/Pet"
+	Primitive   string // schema type of Primitive and Enum, such as "string" or "integer"
+	Format      string // format of the schema, such as "date-time"
+	Description string
+	Nullable    bool
+
+	Fields     []*Field      // properties of Struct, sorted by the name
+	Additional *Type         // additional properties of Struct, nil if not declared
+	Elem       *Type         // element of Slice, value of Map, or referenced schema of Ref which is nil if not found
+	Variants   []*Type       // variants of Union
+	Values     []interface{} // enumerated values of Enum, such as string, float64 and bool
+
+	Constraints
+}
+
+// Field represents a property of Struct.
+type Field struct {
+	Name        string // property name
+	Description string
+	Required    bool
+	Type        *Type
+}
+
+// Constraints represents the validation constraints of a schema.
+type Constraints struct {
+	MinLength    uint64
+	MaxLength    *uint64
+	Pattern      string
+	Min          *float64
+	Max          *float64
+	ExclusiveMin bool
+	ExclusiveMax bool
+	MultipleOf   *float64
+	MinItems     uint64
+	MaxItems     *uint64
+	UniqueItems  bool
+}
+
+// Resolve returns the referenced type if t is Ref, following the references. It returns nil if the referenced
+// schema is not found.
+func (t *Type) Resolve() *Type {
+	for seen := 0; t != nil && t.Kind == Ref; seen++ {
+		if seen > 64 {
+			return nil // circular references
+		}
+		t = t.Elem
+	}
+
+	return t
+}
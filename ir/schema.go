@@ -0,0 +1,191 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package ir
+
+import (
+	pathpkg "path"
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// schemaType returns the type of the schema, or nil if ref is nil.
+//
+// The $ref is the Ref type which Elem is the component schema.
+func (b *builder) schemaType(ref *openapi3.SchemaRef) *Type {
+	switch {
+	case ref == nil:
+		return nil
+
+	case ref.Ref != "":
+		name := pathpkg.Base(ref.Ref)
+		return &Type{
+			Kind: Ref,
+			Name: name,
+			Ref:  ref.Ref,
+			Elem: b.model(name),
+		}
+
+	case ref.Value == nil:
+		return &Type{Kind: Any}
+	}
+
+	s := ref.Value
+	t := &Type{
+		Format:      s.Format,
+		Description: s.Description,
+		Nullable:    s.Nullable,
+		Constraints: Constraints{
+			MinLength:    s.MinLength,
+			MaxLength:    s.MaxLength,
+			Pattern:      s.Pattern,
+			Min:          s.Min,
+			Max:          s.Max,
+			ExclusiveMin: s.ExclusiveMin,
+			ExclusiveMax: s.ExclusiveMax,
+			MultipleOf:   s.MultipleOf,
+			MinItems:     s.MinItems,
+			MaxItems:     s.MaxItems,
+			UniqueItems:  s.UniqueItems,
+		},
+	}
+
+	switch {
+	case len(s.OneOf) > 0 || len(s.AnyOf) > 0:
+		t.Kind = Union
+		for _, variant := range append(append(openapi3.SchemaRefs{}, s.OneOf...), s.AnyOf...) {
+			if v := b.schemaType(variant); v != nil {
+				t.Variants = append(t.Variants, v)
+			}
+		}
+
+	case len(s.AllOf) > 0:
+		b.allOf(t, s)
+
+	case s.Type == "array":
+		t.Kind = Slice
+		t.Elem = b.schemaType(s.Items)
+		if t.Elem == nil {
+			t.Elem = &Type{Kind: Any}
+		}
+
+	case s.Type == "object" || len(s.Properties) > 0:
+		additional := b.schemaType(s.AdditionalProperties)
+		if additional == nil && s.AdditionalPropertiesAllowed != nil && *s.AdditionalPropertiesAllowed {
+			additional = &Type{Kind: Any}
+		}
+		if len(s.Properties) == 0 && additional != nil {
+			t.Kind = Map
+			t.Elem = additional
+			break
+		}
+		t.Kind = Struct
+		t.Additional = additional
+		t.Fields = b.fields(s)
+
+	case len(s.Enum) > 0:
+		t.Kind = Enum
+		t.Primitive = s.Type
+		t.Values = s.Enum
+
+	case s.Type == "":
+		t.Kind = Any
+
+	default:
+		t.Kind = Primitive
+		t.Primitive = s.Type
+	}
+
+	return t
+}
+
+// model returns the type of the component schema, which is built at the first call. It returns nil if not found.
+//
+// The type is allocated before building, so the circular references refer to the same type.
+func (b *builder) model(name string) *Type {
+	t, ok := b.models[name]
+	if !ok {
+		return nil
+	}
+	if !b.built[name] {
+		b.built[name] = true
+		if built := b.schemaType(b.doc.Components.Schemas[name]); built != nil {
+			*t = *built
+		}
+	}
+
+	return t
+}
+
+// fields returns the properties of the object schema, sorted by the name.
+func (b *builder) fields(s *openapi3.Schema) []*Field {
+	fields := make([]*Field, 0, len(s.Properties))
+	for _, name := range sortedKeys(s.Properties) {
+		field := &Field{
+			Name:     name,
+			Required: contains(name, s.Required),
+			Type:     b.schemaType(s.Properties[name]),
+		}
+		if field.Type != nil {
+			field.Description = field.Type.Description
+		}
+		fields = append(fields, field)
+	}
+
+	return fields
+}
+
+// allOf sets t to the struct which merges the properties of the allOf schemas and s itself.
+//
+// The single allOf schema which is not an object, such as the $ref to the enum, is the type of the schema.
+func (b *builder) allOf(t *Type, s *openapi3.Schema) {
+	if len(s.AllOf) == 1 && len(s.Properties) == 0 {
+		part := b.schemaType(s.AllOf[0])
+		if resolved := part.Resolve(); resolved != nil && resolved.Kind != Struct {
+			desc := t.Description
+			*t = *part
+			if desc != "" {
+				t.Description = desc
+			}
+			return
+		}
+	}
+
+	t.Kind = Struct
+	index := make(map[string]int) // field name to the index of t.Fields
+	merge := func(fields []*Field) {
+		for _, f := range fields {
+			if i, ok := index[f.Name]; ok {
+				t.Fields[i] = f
+				continue
+			}
+			index[f.Name] = len(t.Fields)
+			t.Fields = append(t.Fields, f)
+		}
+	}
+	for _, ref := range s.AllOf {
+		part := b.schemaType(ref).Resolve()
+		if part == nil || part.Kind != Struct {
+			continue
+		}
+		merge(part.Fields)
+		if t.Additional == nil {
+			t.Additional = part.Additional
+		}
+	}
+	merge(b.fields(s))
+
+	sort.SliceStable(t.Fields, func(i, j int) bool { return t.Fields[i].Name < t.Fields[j].Name })
+}
+
+// contains reports whether the s is in list.
+func contains(s string, list []string) bool {
+	for _, v := range list {
+		if v == s {
+			return true
+		}
+	}
+
+	return false
+}
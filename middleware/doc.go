@@ -0,0 +1,6 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package middleware provides the net/http middleware which validates requests and responses against the OpenAPI schema
+// embedded in the code generated by oapi-generator.
+package middleware
@@ -0,0 +1,81 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package middleware
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+
+	json "github.com/goccy/go-json"
+)
+
+// mimeProblemJSON is the media type of RFC 7807 problem details.
+const mimeProblemJSON = "application/problem+json"
+
+// Problem represents a RFC 7807 problem details response.
+type Problem struct {
+	Type   string          `json:"type,omitempty"`
+	Title  string          `json:"title"`
+	Status int             `json:"status"`
+	Detail string          `json:"detail,omitempty"`
+	Errors []*InvalidParam `json:"errors,omitempty"`
+}
+
+// InvalidParam represents a validation error of a request parameter, body or response.
+type InvalidParam struct {
+	// Name is the parameter name, if any.
+	Name string `json:"name,omitempty"`
+	// In is the location of the invalid value. one of (path, query, header, cookie, body, response).
+	In string `json:"in,omitempty"`
+	// Pointer is the JSON pointer to the invalid value in the body, if any.
+	Pointer string `json:"pointer,omitempty"`
+	// Reason is the human readable reason of the error.
+	Reason string `json:"reason"`
+}
+
+// String returns a string representation of the InvalidParam.
+func (ip *InvalidParam) String() string {
+	switch {
+	case ip.Name != "":
+		return ip.In + " parameter " + strconv.Quote(ip.Name) + ": " + ip.Reason
+	case ip.Pointer != "":
+		return ip.In + " " + ip.Pointer + ": " + ip.Reason
+	default:
+		return ip.Reason
+	}
+}
+
+// Error implements error.
+func (p *Problem) Error() string {
+	return p.Title + ": " + p.Detail
+}
+
+// WriteProblem writes p as the application/problem+json response.
+func WriteProblem(w http.ResponseWriter, r *http.Request, p *Problem) {
+	if p.Type == "" {
+		p.Type = "about:blank"
+	}
+
+	w.Header().Set("Content-Type", mimeProblemJSON)
+	w.Header().Set("X-Content-Type-Options", "nosniff")
+	w.WriteHeader(p.Status)
+	_ = json.NewEncoder(w).Encode(p)
+}
+
+// jsonPointer returns the RFC 6901 JSON pointer of path.
+func jsonPointer(path []string) string {
+	if len(path) == 0 {
+		return ""
+	}
+
+	var sb strings.Builder
+	r := strings.NewReplacer("~", "~0", "/", "~1")
+	for _, p := range path {
+		sb.WriteByte('/')
+		sb.WriteString(r.Replace(p))
+	}
+
+	return sb.String()
+}
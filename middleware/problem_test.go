@@ -0,0 +1,73 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package middleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	json "github.com/goccy/go-json"
+)
+
+func TestWriteProblem(t *testing.T) {
+	rec := httptest.NewRecorder()
+	WriteProblem(rec, httptest.NewRequest(http.MethodGet, "/", nil), &Problem{
+		Title:  "Request validation failed",
+		Status: http.StatusBadRequest,
+		Detail: "query parameter \"limit\": number must be at most 100",
+		Errors: []*InvalidParam{{Name: "limit", In: "query", Reason: "number must be at most 100"}},
+	})
+
+	resp := rec.Result()
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status code = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != mimeProblemJSON {
+		t.Errorf("Content-Type = %q, want %q", ct, mimeProblemJSON)
+	}
+	if got := resp.Header.Get("X-Content-Type-Options"); got != "nosniff" {
+		t.Errorf("X-Content-Type-Options = %q, want nosniff", got)
+	}
+
+	var got map[string]interface{}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if got["type"] != "about:blank" || got["title"] != "Request validation failed" || got["status"] != float64(http.StatusBadRequest) {
+		t.Errorf("problem = %v, want the RFC 7807 members", got)
+	}
+}
+
+func TestInvalidParamString(t *testing.T) {
+	tests := map[string]struct {
+		ip   *InvalidParam
+		want string
+	}{
+		"Param":   {ip: &InvalidParam{Name: "limit", In: "query", Reason: "too large"}, want: `query parameter "limit": too large`},
+		"Pointer": {ip: &InvalidParam{In: "body", Pointer: "/name", Reason: "must be string"}, want: "body /name: must be string"},
+		"Reason":  {ip: &InvalidParam{Reason: "invalid"}, want: "invalid"},
+	}
+	for name, tt := range tests {
+		if got := tt.ip.String(); got != tt.want {
+			t.Errorf("%s: String() = %q, want %q", name, got, tt.want)
+		}
+	}
+}
+
+func TestJSONPointer(t *testing.T) {
+	tests := map[string]struct {
+		path []string
+		want string
+	}{
+		"Empty":   {want: ""},
+		"Tokens":  {path: []string{"pets", "0", "name"}, want: "/pets/0/name"},
+		"Escaped": {path: []string{"a/b", "c~d"}, want: "/a~1b/c~0d"},
+	}
+	for name, tt := range tests {
+		if got := jsonPointer(tt.path); got != tt.want {
+			t.Errorf("%s: jsonPointer(%q) = %q, want %q", name, tt.path, got, tt.want)
+		}
+	}
+}
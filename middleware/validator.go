@@ -0,0 +1,312 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package middleware
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/openapi3filter"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	"github.com/klauspost/compress/gzip"
+
+	"github.com/zchee/go-openapi-tools/internal/schemaformat"
+)
+
+// Validator validates HTTP requests and optionally responses against the OpenAPI schema.
+type Validator struct {
+	router           routers.Router
+	options          *openapi3filter.Options
+	validateResponse bool
+	keepServers      bool
+	defineFormats    bool
+	errorHandler     ErrorHandlerFunc
+}
+
+// ErrorHandlerFunc writes the validation error response.
+type ErrorHandlerFunc func(w http.ResponseWriter, r *http.Request, problem *Problem)
+
+// Option configures the Validator.
+type Option func(*Validator)
+
+// WithResponseValidation also validates the responses of wrapped handler.
+//
+// The invalid response is replaced to 500 Internal Server Error problem response.
+func WithResponseValidation() Option {
+	return func(v *Validator) {
+		v.validateResponse = true
+	}
+}
+
+// WithOptions sets openapi3filter.Options used by request and response validation.
+//
+// The default options skip the security requirement validation by openapi3filter.NoopAuthenticationFunc.
+func WithOptions(opts *openapi3filter.Options) Option {
+	return func(v *Validator) {
+		v.options = opts
+	}
+}
+
+// WithServers matches the request to the servers declared in the schema.
+//
+// By default, the servers are ignored and the request path is matched to the schema paths as is, the same as the
+// generated server router.
+func WithServers() Option {
+	return func(v *Validator) {
+		v.keepServers = true
+	}
+}
+
+// WithCommonFormats validates the uuid, ipv4 and ipv6 string formats, which kin-openapi does not validate by default.
+//
+// kin-openapi has no string formats per validator, the formats are defined in the process wide
+// openapi3.SchemaStringFormats when the Validator is created. The formats defined by the others are kept.
+func WithCommonFormats() Option {
+	return func(v *Validator) {
+		v.defineFormats = true
+	}
+}
+
+// WithErrorHandler sets the handler which writes the problem response.
+func WithErrorHandler(fn ErrorHandlerFunc) Option {
+	return func(v *Validator) {
+		v.errorHandler = fn
+	}
+}
+
+// New returns the new Validator from the gzipped JSON schema descriptor, which is embedded as fileDescriptor in the
+// generated code.
+func New(descriptor []byte, opts ...Option) (*Validator, error) {
+	zr, err := gzip.NewReader(bytes.NewReader(descriptor))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema descriptor: %w", err)
+	}
+	defer zr.Close()
+
+	data, err := io.ReadAll(zr)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read schema descriptor: %w", err)
+	}
+
+	doc, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to load schema: %w", err)
+	}
+
+	return NewFromDocument(doc, opts...)
+}
+
+// NewFromDocument returns the new Validator from doc.
+func NewFromDocument(doc *openapi3.T, opts ...Option) (*Validator, error) {
+	v := &Validator{
+		options: &openapi3filter.Options{
+			AuthenticationFunc: openapi3filter.NoopAuthenticationFunc,
+		},
+		errorHandler: WriteProblem,
+	}
+	for _, opt := range opts {
+		opt(v)
+	}
+	if v.defineFormats {
+		schemaformat.Define()
+	}
+
+	if !v.keepServers {
+		// shallow copy, do not modify the caller's document
+		d := *doc
+		d.Servers = nil
+		doc = &d
+	}
+
+	router, err := legacy.NewRouter(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create router: %w", err)
+	}
+	v.router = router
+
+	return v, nil
+}
+
+// Middleware returns the net/http middleware which validates requests to next.
+func (v *Validator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		route, pathParams, err := v.router.FindRoute(r)
+		if err != nil {
+			v.errorHandler(w, r, routeProblem(err))
+			return
+		}
+
+		reqInput := &openapi3filter.RequestValidationInput{
+			Request:    r,
+			PathParams: pathParams,
+			Route:      route,
+			Options:    v.options,
+		}
+		if err := openapi3filter.ValidateRequest(r.Context(), reqInput); err != nil {
+			v.errorHandler(w, r, requestProblem(err))
+			return
+		}
+
+		if !v.validateResponse {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		rec := &responseRecorder{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(rec, r)
+
+		respInput := &openapi3filter.ResponseValidationInput{
+			RequestValidationInput: reqInput,
+			Status:                 rec.status,
+			Header:                 rec.header,
+			Options:                v.options,
+		}
+		respInput.SetBodyBytes(rec.body.Bytes())
+		if err := openapi3filter.ValidateResponse(r.Context(), respInput); err != nil {
+			v.errorHandler(w, r, responseProblem(err))
+			return
+		}
+
+		rec.flush(w)
+	})
+}
+
+// responseRecorder records the response of wrapped handler for validation.
+type responseRecorder struct {
+	header      http.Header
+	status      int
+	wroteHeader bool
+	body        bytes.Buffer
+}
+
+var _ http.ResponseWriter = (*responseRecorder)(nil)
+
+// Header implements http.ResponseWriter.
+func (rec *responseRecorder) Header() http.Header { return rec.header }
+
+// WriteHeader implements http.ResponseWriter.
+func (rec *responseRecorder) WriteHeader(status int) {
+	if rec.wroteHeader {
+		return
+	}
+	rec.status = status
+	rec.wroteHeader = true
+}
+
+// Write implements http.ResponseWriter.
+func (rec *responseRecorder) Write(p []byte) (int, error) {
+	rec.WriteHeader(http.StatusOK)
+	return rec.body.Write(p)
+}
+
+// flush writes the recorded response to w.
+func (rec *responseRecorder) flush(w http.ResponseWriter) {
+	for key, values := range rec.header {
+		w.Header()[key] = values
+	}
+	w.WriteHeader(rec.status)
+	_, _ = w.Write(rec.body.Bytes())
+}
+
+// routeProblem returns the Problem of FindRoute error.
+func routeProblem(err error) *Problem {
+	status := http.StatusNotFound
+	if isMethodNotAllowed(err) {
+		status = http.StatusMethodNotAllowed
+	}
+
+	return &Problem{
+		Title:  http.StatusText(status),
+		Status: status,
+		Detail: err.Error(),
+	}
+}
+
+// isMethodNotAllowed reports whether err of FindRoute is routers.ErrMethodNotAllowed. The legacy router returns
+// *routers.RouteError which has the reason of the error instead of wrapping it.
+func isMethodNotAllowed(err error) bool {
+	var routeErr *routers.RouteError
+	if errors.As(err, &routeErr) && routeErr.Reason == routers.ErrMethodNotAllowed.Error() {
+		return true
+	}
+
+	return errors.Is(err, routers.ErrMethodNotAllowed)
+}
+
+// requestProblem returns the Problem of ValidateRequest error.
+func requestProblem(err error) *Problem {
+	p := &Problem{
+		Title:  "Request validation failed",
+		Status: http.StatusBadRequest,
+		Detail: err.Error(),
+	}
+
+	var secErr *openapi3filter.SecurityRequirementsError
+	if errors.As(err, &secErr) {
+		p.Title = http.StatusText(http.StatusUnauthorized)
+		p.Status = http.StatusUnauthorized
+		return p
+	}
+
+	var errs openapi3.MultiError
+	if !errors.As(err, &errs) {
+		errs = openapi3.MultiError{err}
+	}
+	reasons := make([]string, 0, len(errs))
+	for _, e := range errs {
+		ip := invalidParam(e)
+		p.Errors = append(p.Errors, ip)
+		reasons = append(reasons, ip.String())
+	}
+	p.Detail = strings.Join(reasons, "; ")
+
+	return p
+}
+
+// responseProblem returns the Problem of ValidateResponse error.
+func responseProblem(err error) *Problem {
+	ip := invalidParam(err)
+
+	return &Problem{
+		Title:  "Response validation failed",
+		Status: http.StatusInternalServerError,
+		Detail: ip.String(),
+		Errors: []*InvalidParam{ip},
+	}
+}
+
+// invalidParam returns the InvalidParam of a validation error.
+func invalidParam(err error) *InvalidParam {
+	ip := &InvalidParam{Reason: err.Error()}
+
+	var reqErr *openapi3filter.RequestError
+	if errors.As(err, &reqErr) {
+		switch {
+		case reqErr.Parameter != nil:
+			ip.Name = reqErr.Parameter.Name
+			ip.In = reqErr.Parameter.In
+		case reqErr.RequestBody != nil:
+			ip.In = "body"
+		}
+	}
+
+	var respErr *openapi3filter.ResponseError
+	if errors.As(err, &respErr) {
+		ip.In = "response"
+	}
+
+	var schemaErr *openapi3.SchemaError
+	if errors.As(err, &schemaErr) {
+		ip.Pointer = jsonPointer(schemaErr.JSONPointer())
+		ip.Reason = schemaErr.Reason
+	}
+
+	return ip
+}
@@ -0,0 +1,319 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package middleware
+
+import (
+	"bytes"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/klauspost/compress/gzip"
+
+	json "github.com/goccy/go-json"
+)
+
+const testSchema = `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+servers:
+  - url: http://example.com/v1
+paths:
+  /pets:
+    get:
+      parameters:
+        - name: limit
+          in: query
+          schema:
+            type: integer
+            maximum: 100
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+    post:
+      requestBody:
+        required: true
+        content:
+          application/json:
+            schema:
+              $ref: '#/components/schemas/Pet'
+      responses:
+        '201':
+          description: Created
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [name]
+      properties:
+        name:
+          type: string
+`
+
+// formatSchema is the schema which uses the uuid format.
+const formatSchema = `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+paths:
+  /pets/{id}:
+    get:
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: string
+            format: uuid
+      responses:
+        '204':
+          description: No content
+`
+
+// newTestValidator returns the Validator of schema which wraps handler.
+func newTestValidator(t *testing.T, schema string, handler http.Handler, opts ...Option) http.Handler {
+	t.Helper()
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(schema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	v, err := NewFromDocument(doc, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return v.Middleware(handler)
+}
+
+// serve serves the request to h and returns the response.
+func serve(h http.Handler, method, target, body string) *http.Response {
+	var r io.Reader
+	if body != "" {
+		r = strings.NewReader(body)
+	}
+	req := httptest.NewRequest(method, target, r)
+	if body != "" {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	rec := httptest.NewRecorder()
+	h.ServeHTTP(rec, req)
+
+	return rec.Result()
+}
+
+// decodeProblem decodes the problem response, and reports the error if resp is not the problem of status.
+func decodeProblem(t *testing.T, resp *http.Response, status int) *Problem {
+	t.Helper()
+
+	if resp.StatusCode != status {
+		t.Fatalf("status code = %d, want %d", resp.StatusCode, status)
+	}
+	if ct := resp.Header.Get("Content-Type"); ct != mimeProblemJSON {
+		t.Errorf("Content-Type = %q, want %q", ct, mimeProblemJSON)
+	}
+	var p Problem
+	if err := json.NewDecoder(resp.Body).Decode(&p); err != nil {
+		t.Fatal(err)
+	}
+	if p.Type != "about:blank" || p.Status != status {
+		t.Errorf("problem = %+v, want the type about:blank and the status %d", p, status)
+	}
+
+	return &p
+}
+
+func TestMiddlewareValidRequest(t *testing.T) {
+	called := false
+	h := newTestValidator(t, testSchema, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		called = true
+		body, _ := io.ReadAll(r.Body)
+		if string(body) != `{"name":"doggie"}` {
+			t.Errorf("body = %q, want the request body", body)
+		}
+		w.WriteHeader(http.StatusCreated)
+	}))
+
+	resp := serve(h, http.MethodPost, "/pets", `{"name":"doggie"}`)
+	if resp.StatusCode != http.StatusCreated || !called {
+		t.Fatalf("status code = %d, called = %t, want the response of the handler", resp.StatusCode, called)
+	}
+}
+
+func TestMiddlewareInvalidRequest(t *testing.T) {
+	h := newTestValidator(t, testSchema, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		t.Error("the handler is called by the invalid request")
+	}))
+
+	tests := map[string]struct {
+		method, target, body string
+		status               int
+		want                 []*InvalidParam // Reason is compared if not empty
+	}{
+		"QueryParam": {
+			method: http.MethodGet,
+			target: "/pets?limit=1000",
+			status: http.StatusBadRequest,
+			want:   []*InvalidParam{{Name: "limit", In: "query"}},
+		},
+		"Body": {
+			method: http.MethodPost,
+			target: "/pets",
+			body:   `{"name":1}`,
+			status: http.StatusBadRequest,
+			want:   []*InvalidParam{{In: "body", Pointer: "/name"}},
+		},
+		"NotFound": {
+			method: http.MethodGet,
+			target: "/owners",
+			status: http.StatusNotFound,
+		},
+		"MethodNotAllowed": {
+			method: http.MethodDelete,
+			target: "/pets",
+			status: http.StatusMethodNotAllowed,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			p := decodeProblem(t, serve(h, tt.method, tt.target, tt.body), tt.status)
+			if p.Title != http.StatusText(tt.status) && p.Title != "Request validation failed" {
+				t.Errorf("Title = %q", p.Title)
+			}
+			if len(p.Errors) != len(tt.want) {
+				t.Fatalf("Errors = %+v, want %d errors", p.Errors, len(tt.want))
+			}
+			for i, ip := range p.Errors {
+				if ip.Reason == "" {
+					t.Errorf("Errors[%d] has no reason", i)
+				}
+				got := *ip
+				got.Reason = ""
+				if !reflect.DeepEqual(&got, tt.want[i]) {
+					t.Errorf("Errors[%d] = %+v, want %+v", i, ip, tt.want[i])
+				}
+			}
+		})
+	}
+}
+
+func TestMiddlewareCommonFormats(t *testing.T) {
+	h := newTestValidator(t, formatSchema, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}), WithCommonFormats())
+
+	if resp := serve(h, http.MethodGet, "/pets/0b5b2f1c-9d4e-4c4f-8c36-b1f0f7b6a2a1", ""); resp.StatusCode != http.StatusNoContent {
+		t.Errorf("status code of the valid uuid = %d, want %d", resp.StatusCode, http.StatusNoContent)
+	}
+	p := decodeProblem(t, serve(h, http.MethodGet, "/pets/42", ""), http.StatusBadRequest)
+	if len(p.Errors) != 1 || p.Errors[0].Name != "id" || p.Errors[0].In != "path" {
+		t.Errorf("Errors = %+v, want the error of the path parameter id", p.Errors)
+	}
+}
+
+func TestMiddlewareResponseValidation(t *testing.T) {
+	tests := map[string]struct {
+		body   string
+		opts   []Option
+		status int
+	}{
+		"Valid": {
+			body:   `[{"name":"doggie"}]`,
+			opts:   []Option{WithResponseValidation()},
+			status: http.StatusOK,
+		},
+		"Invalid": {
+			body:   `[{"name":1}]`,
+			opts:   []Option{WithResponseValidation()},
+			status: http.StatusInternalServerError,
+		},
+		"Disabled": {
+			body:   `[{"name":1}]`,
+			status: http.StatusOK,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			h := newTestValidator(t, testSchema, http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				w.Header().Set("Content-Type", "application/json")
+				_, _ = io.WriteString(w, tt.body)
+			}), tt.opts...)
+
+			resp := serve(h, http.MethodGet, "/pets", "")
+			if tt.status != http.StatusOK {
+				p := decodeProblem(t, resp, tt.status)
+				if p.Title != "Response validation failed" || len(p.Errors) != 1 || p.Errors[0].In != "response" {
+					t.Errorf("problem = %+v, want the response validation failure", p)
+				}
+				return
+			}
+			if resp.StatusCode != tt.status {
+				t.Fatalf("status code = %d, want %d", resp.StatusCode, tt.status)
+			}
+			body, _ := io.ReadAll(resp.Body)
+			if string(body) != tt.body {
+				t.Errorf("body = %q, want %q", body, tt.body)
+			}
+		})
+	}
+}
+
+func TestMiddlewareErrorHandler(t *testing.T) {
+	var got *Problem
+	h := newTestValidator(t, testSchema, http.NotFoundHandler(), WithErrorHandler(func(w http.ResponseWriter, r *http.Request, p *Problem) {
+		got = p
+		w.WriteHeader(http.StatusTeapot)
+	}))
+
+	if resp := serve(h, http.MethodGet, "/pets?limit=1000", ""); resp.StatusCode != http.StatusTeapot {
+		t.Errorf("status code = %d, want %d", resp.StatusCode, http.StatusTeapot)
+	}
+	if got == nil || got.Status != http.StatusBadRequest {
+		t.Errorf("problem = %+v, want the problem of the invalid request", got)
+	}
+}
+
+func TestNew(t *testing.T) {
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	data, err := json.Marshal(doc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	zw := gzip.NewWriter(&buf)
+	if _, err := zw.Write(data); err != nil {
+		t.Fatal(err)
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	v, err := New(buf.Bytes())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp := serve(v.Middleware(http.NotFoundHandler()), http.MethodGet, "/pets?limit=1000", ""); resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("status code = %d, want %d", resp.StatusCode, http.StatusBadRequest)
+	}
+
+	if _, err := New([]byte("not gzip")); err == nil {
+		t.Error("New() of the invalid descriptor returns no error")
+	}
+}
@@ -0,0 +1,6 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+// Package mock provides the net/http mock server handler which serves the responses built from the examples of the
+// OpenAPI schema, or synthesized from the schema when no example exists.
+package mock
@@ -0,0 +1,167 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package mock
+
+import (
+	"sort"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+// maxDepth is the maximum depth of the synthesized value, to stop the recursive schema.
+const maxDepth = 8
+
+// Example returns the example value of mt.
+//
+// The named example is used if name is not empty and exists, otherwise the example, the first of examples sorted by
+// name, the schema example, and the value synthesized from the schema are used in that order.
+func Example(mt *openapi3.MediaType, name string) interface{} {
+	if name != "" {
+		if ex, ok := mt.Examples[name]; ok && ex != nil && ex.Value != nil {
+			return ex.Value.Value
+		}
+	}
+	if mt.Example != nil {
+		return mt.Example
+	}
+	if len(mt.Examples) > 0 {
+		names := make([]string, 0, len(mt.Examples))
+		for name := range mt.Examples {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		if ex := mt.Examples[names[0]]; ex != nil && ex.Value != nil {
+			return ex.Value.Value
+		}
+	}
+	if mt.Schema == nil {
+		return nil
+	}
+
+	return Synthesize(mt.Schema.Value)
+}
+
+// Synthesize returns the value synthesized from schema.
+//
+// The example, default and the first enum of the schema are preferred to the synthesized value.
+func Synthesize(schema *openapi3.Schema) interface{} {
+	return synthesize(schema, 0)
+}
+
+// formatExamples is the map of the string format to the example value.
+var formatExamples = map[string]string{
+	"date":      "2006-01-02",
+	"date-time": "2006-01-02T15:04:05Z",
+	"email":     "user@example.com",
+	"hostname":  "example.com",
+	"ipv4":      "192.0.2.1",
+	"ipv6":      "2001:db8::1",
+	"uri":       "https://example.com",
+	"uuid":      "00000000-0000-0000-0000-000000000000",
+	"byte":      "ZXhhbXBsZQ==",
+}
+
+func synthesize(schema *openapi3.Schema, depth int) interface{} {
+	if schema == nil || depth > maxDepth {
+		return nil
+	}
+
+	switch {
+	case schema.Example != nil:
+		return schema.Example
+	case schema.Default != nil:
+		return schema.Default
+	case len(schema.Enum) > 0:
+		return schema.Enum[0]
+	}
+
+	switch {
+	case len(schema.AllOf) > 0:
+		v := make(map[string]interface{})
+		for _, s := range schema.AllOf {
+			if m, ok := synthesize(s.Value, depth+1).(map[string]interface{}); ok {
+				for key, val := range m {
+					v[key] = val
+				}
+			}
+		}
+		return v
+
+	case len(schema.OneOf) > 0:
+		return synthesize(schema.OneOf[0].Value, depth+1)
+
+	case len(schema.AnyOf) > 0:
+		return synthesize(schema.AnyOf[0].Value, depth+1)
+	}
+
+	switch schema.Type {
+	case "object", "":
+		v := make(map[string]interface{}, len(schema.Properties))
+		for name, prop := range schema.Properties {
+			if prop == nil {
+				continue
+			}
+			// omit the optional recursive properties
+			if pv := synthesize(prop.Value, depth+1); pv != nil {
+				v[name] = pv
+			}
+		}
+		return v
+
+	case "array":
+		if schema.Items == nil {
+			return []interface{}{}
+		}
+		item := synthesize(schema.Items.Value, depth+1)
+		if item == nil {
+			return []interface{}{}
+		}
+		n := int(schema.MinItems)
+		if n == 0 {
+			n = 1
+		}
+		v := make([]interface{}, n)
+		for i := range v {
+			v[i] = item
+		}
+		return v
+
+	case "string":
+		if ex, ok := formatExamples[schema.Format]; ok {
+			return ex
+		}
+		s := "string"
+		for uint64(len(s)) < schema.MinLength {
+			s += s
+		}
+		if schema.MaxLength != nil && uint64(len(s)) > *schema.MaxLength {
+			s = s[:*schema.MaxLength]
+		}
+		return s
+
+	case "integer":
+		if schema.Min != nil {
+			n := int64(*schema.Min)
+			if schema.ExclusiveMin || float64(n) < *schema.Min {
+				n++
+			}
+			return n
+		}
+		return 0
+
+	case "number":
+		if schema.Min != nil {
+			if schema.ExclusiveMin {
+				return *schema.Min + 1
+			}
+			return *schema.Min
+		}
+		return 0.0
+
+	case "boolean":
+		return true
+	}
+
+	return nil
+}
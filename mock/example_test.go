@@ -0,0 +1,97 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package mock
+
+import (
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+)
+
+func TestExample(t *testing.T) {
+	schema := openapi3.NewSchemaRef("", openapi3.NewStringSchema())
+	examples := openapi3.Examples{
+		"b": &openapi3.ExampleRef{Value: openapi3.NewExample("b value")},
+		"a": &openapi3.ExampleRef{Value: openapi3.NewExample("a value")},
+	}
+
+	tests := map[string]struct {
+		mt   *openapi3.MediaType
+		name string
+		want interface{}
+	}{
+		"Named":         {mt: &openapi3.MediaType{Schema: schema, Example: "example", Examples: examples}, name: "b", want: "b value"},
+		"UnknownName":   {mt: &openapi3.MediaType{Schema: schema, Example: "example", Examples: examples}, name: "c", want: "example"},
+		"Example":       {mt: &openapi3.MediaType{Schema: schema, Example: "example"}, want: "example"},
+		"FirstExamples": {mt: &openapi3.MediaType{Schema: schema, Examples: examples}, want: "a value"},
+		"SchemaExample": {mt: &openapi3.MediaType{Schema: openapi3.NewSchemaRef("", &openapi3.Schema{Type: "string", Example: "schema"})}, want: "schema"},
+		"Synthesized":   {mt: &openapi3.MediaType{Schema: schema}, want: "string"},
+		"NoSchema":      {mt: &openapi3.MediaType{}, want: nil},
+	}
+	for name, tt := range tests {
+		if got := Example(tt.mt, tt.name); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Example = %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+func TestSynthesize(t *testing.T) {
+	maxLen := uint64(3)
+	min := 2.5
+
+	recursive := openapi3.NewObjectSchema()
+	recursive.Properties = openapi3.Schemas{
+		"name":  openapi3.NewSchemaRef("", openapi3.NewStringSchema()),
+		"child": &openapi3.SchemaRef{Value: recursive},
+	}
+
+	tests := map[string]struct {
+		schema *openapi3.Schema
+		want   interface{}
+	}{
+		"Nil":          {schema: nil, want: nil},
+		"Default":      {schema: &openapi3.Schema{Type: "integer", Default: 5}, want: 5},
+		"Enum":         {schema: &openapi3.Schema{Type: "string", Enum: []interface{}{"a", "b"}}, want: "a"},
+		"Format":       {schema: &openapi3.Schema{Type: "string", Format: "uuid"}, want: "00000000-0000-0000-0000-000000000000"},
+		"MaxLength":    {schema: &openapi3.Schema{Type: "string", MaxLength: &maxLen}, want: "str"},
+		"IntegerMin":   {schema: &openapi3.Schema{Type: "integer", Min: &min}, want: int64(3)},
+		"ExclusiveMin": {schema: &openapi3.Schema{Type: "number", Min: &min, ExclusiveMin: true}, want: 3.5},
+		"Boolean":      {schema: openapi3.NewBoolSchema(), want: true},
+		"Array": {
+			schema: &openapi3.Schema{Type: "array", MinItems: 2, Items: openapi3.NewSchemaRef("", openapi3.NewBoolSchema())},
+			want:   []interface{}{true, true},
+		},
+		"AllOf": {
+			schema: &openapi3.Schema{AllOf: openapi3.SchemaRefs{
+				openapi3.NewSchemaRef("", openapi3.NewObjectSchema().WithProperty("a", openapi3.NewBoolSchema())),
+				openapi3.NewSchemaRef("", openapi3.NewObjectSchema().WithProperty("b", openapi3.NewInt64Schema())),
+			}},
+			want: map[string]interface{}{"a": true, "b": 0},
+		},
+		"OneOf": {
+			schema: &openapi3.Schema{OneOf: openapi3.SchemaRefs{openapi3.NewSchemaRef("", openapi3.NewBoolSchema())}},
+			want:   true,
+		},
+	}
+	for name, tt := range tests {
+		if got := Synthesize(tt.schema); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("%s: Synthesize = %#v, want %#v", name, got, tt.want)
+		}
+	}
+
+	// the recursive schema is stopped at maxDepth
+	v := Synthesize(recursive).(map[string]interface{})
+	depth := 0
+	for child, ok := v["child"].(map[string]interface{}); ok; child, ok = v["child"].(map[string]interface{}) {
+		if v["name"] != "string" {
+			t.Fatalf("depth %d: name = %v, want string", depth, v["name"])
+		}
+		v = child
+		depth++
+	}
+	if depth != maxDepth {
+		t.Errorf("depth = %d, want %d", depth, maxDepth)
+	}
+}
@@ -0,0 +1,209 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package mock
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/getkin/kin-openapi/openapi3"
+	"github.com/getkin/kin-openapi/routers"
+	"github.com/getkin/kin-openapi/routers/legacy"
+	json "github.com/goccy/go-json"
+)
+
+const (
+	hdrContentType = "Content-Type"
+	hdrPrefer      = "Prefer"
+	mimeJSON       = "application/json"
+)
+
+// Handler serves the mock responses of the OpenAPI schema.
+type Handler struct {
+	router      routers.Router
+	keepServers bool
+}
+
+var _ http.Handler = (*Handler)(nil)
+
+// Option configures the Handler.
+type Option func(*Handler)
+
+// WithServers matches the request to the servers declared in the schema.
+//
+// By default, the servers are ignored and the request path is matched to the schema paths as is.
+func WithServers() Option {
+	return func(h *Handler) {
+		h.keepServers = true
+	}
+}
+
+// NewHandler returns the new mock Handler of doc.
+//
+// The $ref in doc is resolved on the copy of doc, it does not modify doc.
+func NewHandler(doc *openapi3.T, opts ...Option) (*Handler, error) {
+	h := &Handler{}
+	for _, opt := range opts {
+		opt(h)
+	}
+
+	data, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal schema: %w", err)
+	}
+	resolved, err := openapi3.NewLoader().LoadFromData(data)
+	if err != nil {
+		return nil, fmt.Errorf("failed to resolve schema: %w", err)
+	}
+	if !h.keepServers {
+		resolved.Servers = nil
+	}
+
+	router, err := legacy.NewRouter(resolved)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create router: %w", err)
+	}
+	h.router = router
+
+	return h, nil
+}
+
+// ServeHTTP implements http.Handler.
+//
+// The response is selected by the Prefer request header, such as "Prefer: code=404" or "Prefer: example=cat".
+// By default, the lowest 2xx response is served.
+func (h *Handler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	route, _, err := h.router.FindRoute(r)
+	if err != nil {
+		status := http.StatusNotFound
+		if isMethodNotAllowed(err) {
+			status = http.StatusMethodNotAllowed
+		}
+		http.Error(w, err.Error(), status)
+		return
+	}
+
+	prefer := parsePrefer(r.Header.Values(hdrPrefer))
+	code, resp, err := selectResponse(route.Operation, prefer["code"])
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusNotImplemented)
+		return
+	}
+
+	for name, hdr := range resp.Headers {
+		if hdr == nil || hdr.Value == nil {
+			continue
+		}
+		v := hdr.Value.Example
+		if v == nil && hdr.Value.Schema != nil {
+			v = Synthesize(hdr.Value.Schema.Value)
+		}
+		if v != nil {
+			w.Header().Set(name, fmt.Sprint(v))
+		}
+	}
+
+	mediaType, mt := selectMediaType(resp.Content)
+	if mt == nil {
+		w.WriteHeader(code)
+		return
+	}
+
+	body, err := json.Marshal(Example(mt, prefer["example"]))
+	if err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set(hdrContentType, mediaType)
+	w.WriteHeader(code)
+	_, _ = w.Write(body)
+}
+
+// isMethodNotAllowed reports whether err of FindRoute is routers.ErrMethodNotAllowed. The legacy router returns
+// *routers.RouteError which has the reason of the error instead of wrapping it.
+func isMethodNotAllowed(err error) bool {
+	var routeErr *routers.RouteError
+	if errors.As(err, &routeErr) && routeErr.Reason == routers.ErrMethodNotAllowed.Error() {
+		return true
+	}
+
+	return errors.Is(err, routers.ErrMethodNotAllowed)
+}
+
+// parsePrefer parses the Prefer header values to the map of preference name to value.
+func parsePrefer(values []string) map[string]string {
+	prefer := make(map[string]string)
+	for _, v := range values {
+		for _, pref := range strings.Split(v, ",") {
+			for _, kv := range strings.Split(pref, ";") {
+				kv = strings.TrimSpace(kv)
+				if idx := strings.Index(kv, "="); idx > -1 {
+					prefer[strings.ToLower(kv[:idx])] = strings.Trim(kv[idx+1:], `"`)
+				}
+			}
+		}
+	}
+
+	return prefer
+}
+
+// selectResponse selects the response of op by the preferred status code.
+//
+// If code is empty, selects the lowest 2xx response, or default response.
+func selectResponse(op *openapi3.Operation, code string) (int, *openapi3.Response, error) {
+	if code != "" {
+		status, err := strconv.Atoi(code)
+		if err != nil {
+			return 0, nil, fmt.Errorf("invalid preferred code %q", code)
+		}
+		if resp := op.Responses.Get(status); resp != nil && resp.Value != nil {
+			return status, resp.Value, nil
+		}
+		if resp := op.Responses.Default(); resp != nil && resp.Value != nil {
+			return status, resp.Value, nil
+		}
+		return 0, nil, fmt.Errorf("no %s response defined for %s", code, op.OperationID)
+	}
+
+	codes := make([]string, 0, len(op.Responses))
+	for code := range op.Responses {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	for _, code := range codes {
+		if status, err := strconv.Atoi(code); err == nil && status >= 200 && status <= 299 {
+			if resp := op.Responses[code]; resp != nil && resp.Value != nil {
+				return status, resp.Value, nil
+			}
+		}
+	}
+	if resp := op.Responses.Default(); resp != nil && resp.Value != nil {
+		return http.StatusOK, resp.Value, nil
+	}
+
+	return 0, nil, fmt.Errorf("no success response defined for %s", op.OperationID)
+}
+
+// selectMediaType selects the JSON media type of content, or the first media type sorted by name.
+func selectMediaType(content openapi3.Content) (string, *openapi3.MediaType) {
+	if len(content) == 0 {
+		return "", nil
+	}
+	if mt := content.Get(mimeJSON); mt != nil {
+		return mimeJSON, mt
+	}
+
+	types := make([]string, 0, len(content))
+	for typ := range content {
+		types = append(types, typ)
+	}
+	sort.Strings(types)
+
+	return types[0], content[types[0]]
+}
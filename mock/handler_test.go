@@ -0,0 +1,268 @@
+// Copyright 2022 The go-openapi-tools Authors.
+// SPDX-License-Identifier: BSD-3-Clause
+
+package mock
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+
+	"github.com/getkin/kin-openapi/openapi3"
+
+	json "github.com/goccy/go-json"
+)
+
+const testSchema = `openapi: 3.0.3
+info:
+  title: Pets
+  version: 1.0.0
+servers:
+  - url: http://example.com/v1
+paths:
+  /pets:
+    get:
+      operationId: listPets
+      responses:
+        '200':
+          description: OK
+          headers:
+            X-Total-Count:
+              schema:
+                type: integer
+                minimum: 1
+          content:
+            application/json:
+              schema:
+                type: array
+                items:
+                  $ref: '#/components/schemas/Pet'
+              examples:
+                dog:
+                  value: [{id: 1, name: Pochi, tag: dog}]
+                cat:
+                  value: [{id: 2, name: Tama, tag: cat}]
+        '404':
+          description: Not Found
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+        default:
+          description: Unexpected error
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Error'
+              example:
+                code: 500
+                message: unexpected
+  /pets/{id}:
+    get:
+      operationId: showPet
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        '200':
+          description: OK
+          content:
+            application/json:
+              schema:
+                $ref: '#/components/schemas/Pet'
+    delete:
+      operationId: deletePet
+      parameters:
+        - name: id
+          in: path
+          required: true
+          schema:
+            type: integer
+      responses:
+        '204':
+          description: No Content
+components:
+  schemas:
+    Pet:
+      type: object
+      required: [id, name]
+      properties:
+        id:
+          type: integer
+          minimum: 1
+        name:
+          type: string
+          minLength: 10
+        tag:
+          type: string
+          enum: [dog, cat]
+    Error:
+      type: object
+      properties:
+        code:
+          type: integer
+        message:
+          type: string
+`
+
+func newTestHandler(t *testing.T, opts ...Option) *Handler {
+	t.Helper()
+
+	doc, err := openapi3.NewLoader().LoadFromData([]byte(testSchema))
+	if err != nil {
+		t.Fatal(err)
+	}
+	h, err := NewHandler(doc, opts...)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return h
+}
+
+func TestHandler(t *testing.T) {
+	h := newTestHandler(t)
+
+	tests := map[string]struct {
+		method     string
+		target     string
+		prefer     string
+		wantStatus int
+		wantHeader map[string]string
+		wantBody   interface{}
+	}{
+		"FirstExample": {
+			method:     http.MethodGet,
+			target:     "/pets",
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"Content-Type": "application/json", "X-Total-Count": "1"},
+			wantBody:   []interface{}{map[string]interface{}{"id": float64(2), "name": "Tama", "tag": "cat"}},
+		},
+		"PreferExample": {
+			method:     http.MethodGet,
+			target:     "/pets",
+			prefer:     "example=dog",
+			wantStatus: http.StatusOK,
+			wantBody:   []interface{}{map[string]interface{}{"id": float64(1), "name": "Pochi", "tag": "dog"}},
+		},
+		"PreferCode": {
+			method:     http.MethodGet,
+			target:     "/pets",
+			prefer:     "code=404",
+			wantStatus: http.StatusNotFound,
+			wantBody:   map[string]interface{}{"code": float64(0), "message": "string"},
+		},
+		"PreferCodeAndExample": {
+			method:     http.MethodGet,
+			target:     "/pets",
+			prefer:     `code=200, example="dog"`,
+			wantStatus: http.StatusOK,
+			wantBody:   []interface{}{map[string]interface{}{"id": float64(1), "name": "Pochi", "tag": "dog"}},
+		},
+		"PreferDefault": {
+			method:     http.MethodGet,
+			target:     "/pets",
+			prefer:     "code=503",
+			wantStatus: http.StatusServiceUnavailable,
+			wantBody:   map[string]interface{}{"code": float64(500), "message": "unexpected"},
+		},
+		"PreferUndefinedCode": {
+			method:     http.MethodGet,
+			target:     "/pets/1",
+			prefer:     "code=404",
+			wantStatus: http.StatusNotImplemented,
+		},
+		"PreferInvalidCode": {
+			method:     http.MethodGet,
+			target:     "/pets",
+			prefer:     "code=abc",
+			wantStatus: http.StatusNotImplemented,
+		},
+		"Synthesized": {
+			method:     http.MethodGet,
+			target:     "/pets/1",
+			wantStatus: http.StatusOK,
+			wantHeader: map[string]string{"Content-Type": "application/json"},
+			wantBody:   map[string]interface{}{"id": float64(1), "name": "stringstring", "tag": "dog"},
+		},
+		"NoContent": {
+			method:     http.MethodDelete,
+			target:     "/pets/1",
+			wantStatus: http.StatusNoContent,
+			wantHeader: map[string]string{"Content-Type": ""},
+		},
+		"UnknownPath": {
+			method:     http.MethodGet,
+			target:     "/owners",
+			wantStatus: http.StatusNotFound,
+		},
+		"UnknownMethod": {
+			method:     http.MethodPut,
+			target:     "/pets",
+			wantStatus: http.StatusMethodNotAllowed,
+		},
+	}
+	for name, tt := range tests {
+		tt := tt
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(tt.method, tt.target, nil)
+			if tt.prefer != "" {
+				req.Header.Set(hdrPrefer, tt.prefer)
+			}
+			rec := httptest.NewRecorder()
+			h.ServeHTTP(rec, req)
+
+			resp := rec.Result()
+			if resp.StatusCode != tt.wantStatus {
+				t.Fatalf("status code = %d, want %d: %s", resp.StatusCode, tt.wantStatus, rec.Body)
+			}
+			for key, want := range tt.wantHeader {
+				if got := resp.Header.Get(key); got != want {
+					t.Errorf("header %s = %q, want %q", key, got, want)
+				}
+			}
+			if tt.wantBody == nil {
+				return
+			}
+
+			var got interface{}
+			if err := json.Unmarshal(rec.Body.Bytes(), &got); err != nil {
+				t.Fatal(err)
+			}
+			if !reflect.DeepEqual(got, tt.wantBody) {
+				t.Errorf("body = %v, want %v", got, tt.wantBody)
+			}
+		})
+	}
+}
+
+func TestHandlerWithServers(t *testing.T) {
+	h := newTestHandler(t, WithServers())
+
+	tests := map[string]struct {
+		target     string
+		wantStatus int
+	}{
+		"Server":   {target: "http://example.com/v1/pets", wantStatus: http.StatusOK},
+		"NoServer": {target: "http://example.com/pets", wantStatus: http.StatusNotFound},
+	}
+	for name, tt := range tests {
+		rec := httptest.NewRecorder()
+		h.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, tt.target, nil))
+		if rec.Code != tt.wantStatus {
+			t.Errorf("%s: status code = %d, want %d", name, rec.Code, tt.wantStatus)
+		}
+	}
+}
+
+func TestParsePrefer(t *testing.T) {
+	got := parsePrefer([]string{`code=404; example="cat"`, "respond-async, wait=10"})
+	want := map[string]string{"code": "404", "example": "cat", "wait": "10"}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsePrefer = %v, want %v", got, want)
+	}
+}